@@ -0,0 +1,34 @@
+package classifiers
+
+import (
+	"github.com/project-mac/src/data"
+)
+
+//A classifier that can be trained on a set of instances and that predicts a
+//probability distribution over the values of the nominal class attribute
+type Classifier interface {
+	//Generates the classifier from the given training instances
+	BuildClassifier(instances data.Instances) error
+	//Predicts the class memberships for the given instance, the returned slice
+	//has one entry per class value and sums to one, or all zeros if the
+	//instance could not be classified
+	DistributionForInstance(instance data.Instance) []float64
+}
+
+//Returns a new untrained classifier, used by evaluation methods that have to
+//train several copies of the same scheme (cross-validation, learning curves)
+type Factory func() Classifier
+
+//Returns the index of the class value with the highest probability, or -1 if
+//the distribution is all zeros
+func MaxIndex(dist []float64) int {
+	maxIndex := -1
+	maxProb := 0.0
+	for i, prob := range dist {
+		if prob > maxProb {
+			maxIndex = i
+			maxProb = prob
+		}
+	}
+	return maxIndex
+}
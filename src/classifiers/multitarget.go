@@ -0,0 +1,167 @@
+package classifiers
+
+import (
+	"fmt"
+	"github.com/project-mac/src/data"
+)
+
+//Meta-learner for datasets with several nominal output attributes
+//(multi-label when they are binary, multi-target otherwise), see
+//data.Instances.SetOutputIndices. Binary relevance trains one base
+//classifier per output on the input attributes. A classifier chain also
+//gives each classifier the outputs before it, their true values when
+//training and the predicted ones when predicting
+type MultiTarget struct {
+	//Creates the base classifiers
+	factory Factory
+	//Whether the outputs are chained
+	chain bool
+	//Indexes of the output attributes in the training data
+	outputs []int
+	//Indexes of the input attributes in the training data
+	inputs []int
+	//One classifier per output
+	classifiers []Classifier
+	//Header of the data each classifier is trained on
+	headers []*data.Header
+}
+
+//Creates a binary relevance meta-learner with base classifiers from factory
+func NewBinaryRelevance(factory Factory) MultiTarget {
+	var mt MultiTarget
+	mt.factory = factory
+	mt.chain = false
+	return mt
+}
+
+//Creates a classifier chain with base classifiers from factory, the chain
+//follows the order of the output attributes
+func NewClassifierChain(factory Factory) MultiTarget {
+	mt := NewBinaryRelevance(factory)
+	mt.chain = true
+	return mt
+}
+
+//Trains one classifier per output attribute of the instances
+func (mt *MultiTarget) BuildClassifier(instances data.Instances) error {
+	mt.outputs = instances.OutputIndices()
+	if len(mt.outputs) == 0 {
+		return data.ErrClassNotSet
+	}
+	isOutput := make(map[int]bool, len(mt.outputs))
+	for _, idx := range mt.outputs {
+		if !instances.Attribute(idx).IsNominal() {
+			return fmt.Errorf("MultiTarget: output attribute '%s' is not nominal", instances.Attribute(idx).Name())
+		}
+		isOutput[idx] = true
+	}
+	mt.inputs = make([]int, 0, len(instances.Attributes()))
+	for idx := range instances.Attributes() {
+		if !isOutput[idx] {
+			mt.inputs = append(mt.inputs, idx)
+		}
+	}
+	header := instances.Header()
+	mt.classifiers = make([]Classifier, len(mt.outputs))
+	mt.headers = make([]*data.Header, len(mt.outputs))
+	for k := range mt.outputs {
+		selected := mt.selectedAttributes(k)
+		attrs := make([]data.Attribute, len(selected))
+		for j, idx := range selected {
+			attrs[j] = header.Attribute(idx)
+			attrs[j].SetDirection(data.INPUT)
+		}
+		attrs[len(attrs)-1].SetDirection(data.OUTPUT)
+		train := data.NewInstancesWithClassIndex(len(attrs) - 1)
+		train.SetDatasetName(fmt.Sprintf("%s-%s", instances.DatasetName(), attrs[len(attrs)-1].Name()))
+		train.SetAttributes(attrs)
+		rows := make([]data.Instance, len(instances.Instances()))
+		for i, instance := range instances.Instances() {
+			values := make([]float64, len(selected))
+			for j, idx := range selected {
+				values[j] = instance.Value(idx)
+			}
+			rows[i] = data.NewDenseInstance(instance.Weight(), values)
+		}
+		train.SetInstances(rows)
+		cls := mt.factory()
+		if err := cls.BuildClassifier(train); err != nil {
+			return fmt.Errorf("MultiTarget: output '%s': %s", attrs[len(attrs)-1].Name(), err.Error())
+		}
+		mt.classifiers[k] = cls
+		mt.headers[k] = train.Header()
+	}
+	return nil
+}
+
+//Returns the attributes of the training data used by the classifier of the
+//k-th output: the inputs, the previous outputs in a chain, and the output
+func (mt *MultiTarget) selectedAttributes(k int) []int {
+	selected := make([]int, 0, len(mt.inputs)+k+1)
+	selected = append(selected, mt.inputs...)
+	if mt.chain {
+		selected = append(selected, mt.outputs[:k]...)
+	}
+	return append(selected, mt.outputs[k])
+}
+
+//Predicts the distribution of each output for an instance with the header of
+//the training data, its output values are ignored
+func (mt *MultiTarget) DistributionsForInstance(instance data.Instance) [][]float64 {
+	dists := make([][]float64, len(mt.outputs))
+	//values of the outputs predicted so far, for the chain
+	predicted := make(map[int]float64, len(mt.outputs))
+	for k, cls := range mt.classifiers {
+		selected := mt.selectedAttributes(k)
+		values := make([]float64, len(selected))
+		for j, idx := range selected[:len(selected)-1] {
+			if value, present := predicted[idx]; present {
+				values[j] = value
+			} else {
+				values[j] = instance.Value(idx)
+			}
+		}
+		values[len(values)-1] = data.MissingValue()
+		row := data.NewDenseInstance(instance.Weight(), values)
+		row.SetHeader(mt.headers[k])
+		dists[k] = cls.DistributionForInstance(row)
+		if index := MaxIndex(dists[k]); index >= 0 {
+			predicted[mt.outputs[k]] = float64(index)
+		} else {
+			predicted[mt.outputs[k]] = data.MissingValue()
+		}
+	}
+	return dists
+}
+
+//Predicts the value of each output, missing for the outputs that could not
+//be classified
+func (mt *MultiTarget) PredictInstance(instance data.Instance) []float64 {
+	dists := mt.DistributionsForInstance(instance)
+	predictions := make([]float64, len(dists))
+	for k, dist := range dists {
+		if index := MaxIndex(dist); index >= 0 {
+			predictions[k] = float64(index)
+		} else {
+			predictions[k] = data.MissingValue()
+		}
+	}
+	return predictions
+}
+
+//Gets methods
+
+//Returns the indexes of the output attributes, in the order of the
+//predictions
+func (mt *MultiTarget) Outputs() []int {
+	return mt.outputs
+}
+
+func (mt *MultiTarget) Chain() bool {
+	return mt.chain
+}
+
+//Returns the classifier trained for each output
+func (mt *MultiTarget) Classifiers() []Classifier {
+	return mt.classifiers
+}
@@ -0,0 +1,53 @@
+package classifiers
+
+import (
+	"fmt"
+	"github.com/project-mac/src/data"
+)
+
+//Class for building and using a 0-R classifier. Predicts the mode of the
+//class distribution, useful as a baseline for the evaluation tools
+type ZeroR struct {
+	//Weighted class counts with a Laplace correction
+	counts []float64
+}
+
+func NewZeroR() ZeroR {
+	var z ZeroR
+	z.counts = make([]float64, 0)
+	return z
+}
+
+//Generates the classifier
+func (z *ZeroR) BuildClassifier(instances data.Instances) error {
+	classIndex := instances.ClassIndex()
+	if classIndex < 0 || classIndex >= len(instances.Attributes()) {
+		return fmt.Errorf("ZeroR: class index %d is not valid", classIndex)
+	}
+	classAttr := instances.Attribute(classIndex)
+	if !classAttr.IsNominal() {
+		return fmt.Errorf("ZeroR: class attribute '%s' is not nominal", classAttr.Name())
+	}
+	z.counts = make([]float64, len(classAttr.Values()))
+	sum := float64(len(z.counts))
+	for i := range z.counts {
+		z.counts[i] = 1
+	}
+	for _, inst := range instances.Instances() {
+		if !inst.ClassMissing(classIndex) {
+			z.counts[int(inst.ClassValue(classIndex))] += inst.Weight()
+			sum += inst.Weight()
+		}
+	}
+	for i := range z.counts {
+		z.counts[i] /= sum
+	}
+	return nil
+}
+
+//Returns the class distribution computed on the training data
+func (z *ZeroR) DistributionForInstance(instance data.Instance) []float64 {
+	dist := make([]float64, len(z.counts))
+	copy(dist, z.counts)
+	return dist
+}
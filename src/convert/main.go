@@ -0,0 +1,74 @@
+//Converts datasets between ARFF and the binary format of the data package:
+//
+//	convert [-c classIndex] [-sparse] [-csr] input output
+//
+//The input format is detected from its content and the output format from
+//the output name: ARFF if it ends with .arff, binary otherwise. With -csr the
+//binary output stores the rows so that data.MapBinary can map them in memory
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"github.com/project-mac/src/data"
+	"log"
+	"os"
+	"strings"
+)
+
+func main() {
+	classIndex := flag.Int("c", 0, "1-based index of the class attribute, -1 for the last one, 0 to keep the one of the input")
+	sparse := flag.Bool("sparse", false, "write ARFF output in sparse format")
+	csr := flag.Bool("csr", false, "write binary output as a CSR matrix that can be memory-mapped")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: convert [-c classIndex] [-sparse] [-csr] input output")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	input, output := flag.Arg(0), flag.Arg(1)
+	instances, err := load(input)
+	if err != nil {
+		log.Fatalf("%s: %s", input, err.Error())
+	}
+	if *classIndex == -1 {
+		instances.SetClassIndex(len(instances.Attributes()) - 1)
+	} else if *classIndex > 0 {
+		if *classIndex > len(instances.Attributes()) {
+			log.Fatalf("class index %d is out of range, there are %d attributes", *classIndex, len(instances.Attributes()))
+		}
+		instances.SetClassIndex(*classIndex - 1)
+	}
+	if strings.HasSuffix(strings.ToLower(output), ".arff") {
+		options := data.NewArffOptions()
+		options.Sparse = *sparse
+		err = data.SaveARFF(output, instances, options)
+	} else if *csr {
+		err = data.SaveBinaryCSR(output, instances)
+	} else {
+		err = data.SaveBinary(output, instances)
+	}
+	if err != nil {
+		log.Fatalf("%s: %s", output, err.Error())
+	}
+}
+
+//Loads a dataset in binary format or, if it does not start with the binary
+//magic number, in ARFF format
+func load(path string) (data.Instances, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return data.Instances{}, err
+	}
+	defer file.Close()
+	reader := bufio.NewReader(file)
+	magic, _ := reader.Peek(len(data.BINARY_MAGIC))
+	if string(magic) == data.BINARY_MAGIC {
+		return data.ReadBinary(reader)
+	}
+	return data.ReadARFF(reader)
+}
@@ -0,0 +1,287 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+//ARFF keywords, compared case-insensitively
+const (
+	ARFF_RELATION  = "@relation"
+	ARFF_ATTRIBUTE = "@attribute"
+	ARFF_DATA      = "@data"
+	ARFF_END       = "@end"
+	ARFF_MISSING   = "?"
+)
+
+//Removes a % comment from the line, % characters inside quotes are kept
+func stripComment(line string) string {
+	var quote rune
+	escape := false
+	for i, c := range line {
+		if quote != 0 {
+			if escape {
+				escape = false
+			} else if c == '\\' {
+				escape = true
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '\'' || c == '"' {
+			quote = c
+		} else if c == '%' {
+			return line[:i]
+		}
+	}
+	return line
+}
+
+//Splits s on sep outside quotes, the parts are returned without unquoting
+func splitOutsideQuotes(s string, sep rune) ([]string, error) {
+	parts := make([]string, 0)
+	var quote rune
+	escape := false
+	start := 0
+	for i, c := range s {
+		if quote != 0 {
+			if escape {
+				escape = false
+			} else if c == '\\' {
+				escape = true
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '\'' || c == '"' {
+			quote = c
+		} else if c == sep {
+			parts = append(parts, s[start:i])
+			start = i + len(string(c))
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in '%s'", s)
+	}
+	return append(parts, s[start:]), nil
+}
+
+//Returns the index of the brace closing the one at s[0], -1 if not closed
+func closingBrace(s string) int {
+	var quote rune
+	escape := false
+	for i, c := range s {
+		if quote != 0 {
+			if escape {
+				escape = false
+			} else if c == '\\' {
+				escape = true
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '\'' || c == '"' {
+			quote = c
+		} else if c == '}' {
+			return i
+		}
+	}
+	return -1
+}
+
+//Trims a name or value and removes its quotes if it has them, quoted
+//reports whether it was quoted (a quoted '?' is not a missing value)
+func unquote(s string) (value string, quoted bool, err error) {
+	s = strings.TrimSpace(s)
+	if len(s) == 0 || (s[0] != '\'' && s[0] != '"') {
+		return s, false, nil
+	}
+	quote := rune(s[0])
+	var sb strings.Builder
+	escape := false
+	for i, c := range s[1:] {
+		if escape {
+			switch c {
+			case 'n':
+				sb.WriteRune('\n')
+			case 't':
+				sb.WriteRune('\t')
+			case 'r':
+				sb.WriteRune('\r')
+			default:
+				sb.WriteRune(c)
+			}
+			escape = false
+		} else if c == '\\' {
+			escape = true
+		} else if c == quote {
+			if rest := strings.TrimSpace(s[i+2:]); rest != "" {
+				return "", true, fmt.Errorf("unexpected '%s' after quoted value %s", rest, s[:i+2])
+			}
+			return sb.String(), true, nil
+		} else {
+			sb.WriteRune(c)
+		}
+	}
+	return "", true, fmt.Errorf("unterminated quote in %s", s)
+}
+
+//Splits a header declaration into whitespace separated tokens. Quoted tokens
+//are unquoted and a {...} block is returned as a single token with its braces
+func headerTokens(line string) ([]string, error) {
+	tokens := make([]string, 0)
+	i := 0
+	for i < len(line) {
+		c := rune(line[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '{':
+			end := closingBrace(line[i:])
+			if end < 0 {
+				return nil, fmt.Errorf("missing '}' in '%s'", line)
+			}
+			tokens = append(tokens, line[i:i+end+1])
+			i += end + 1
+		case c == '\'' || c == '"':
+			end := i + 1
+			escape := false
+			for ; end < len(line); end++ {
+				if escape {
+					escape = false
+				} else if line[end] == '\\' {
+					escape = true
+				} else if rune(line[end]) == c {
+					break
+				}
+			}
+			if end >= len(line) {
+				return nil, fmt.Errorf("unterminated quote in '%s'", line)
+			}
+			token, _, err := unquote(line[i : end+1])
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token)
+			i = end + 1
+		default:
+			end := i
+			for end < len(line) && !unicode.IsSpace(rune(line[end])) && line[end] != '{' {
+				end++
+			}
+			tokens = append(tokens, line[i:end])
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+//Reports whether the (comment free) line starts with the given keyword
+func isDeclaration(line, keyword string) bool {
+	fields := strings.Fields(line)
+	return len(fields) > 0 && strings.EqualFold(fields[0], keyword)
+}
+
+//Splits the body of a {...} nominal specification into its unquoted values
+func nominalSpec(spec string) ([]string, error) {
+	body := strings.TrimSpace(spec[1 : len(spec)-1])
+	if body == "" {
+		return []string{}, nil
+	}
+	parts, err := splitOutsideQuotes(body, ',')
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, len(parts))
+	for i, part := range parts {
+		value, _, err := unquote(part)
+		if err != nil {
+			return nil, err
+		}
+		if value == "" {
+			return nil, fmt.Errorf("empty nominal value in %s", spec)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+//Splits an instance line into its value fields (or "index value" items for
+//sparse rows), whether it is sparse, and the weight given in a trailing {w}
+func splitInstanceLine(line string) (fields []string, sparse bool, weight string, err error) {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "{") {
+		end := closingBrace(line)
+		if end < 0 {
+			return nil, true, "", fmt.Errorf("missing '}' in sparse instance")
+		}
+		body := strings.TrimSpace(line[1:end])
+		rest := strings.TrimSpace(line[end+1:])
+		if rest != "" {
+			if !strings.HasPrefix(rest, ",") {
+				return nil, true, "", fmt.Errorf("unexpected '%s' after sparse instance", rest)
+			}
+			weight, err = weightSpec(strings.TrimSpace(rest[1:]))
+			if err != nil {
+				return nil, true, "", err
+			}
+		}
+		if body == "" {
+			return []string{}, true, weight, nil
+		}
+		fields, err = splitOutsideQuotes(body, ',')
+		return fields, true, weight, err
+	}
+	fields, err = splitOutsideQuotes(line, ',')
+	if err != nil {
+		return nil, false, "", err
+	}
+	last := strings.TrimSpace(fields[len(fields)-1])
+	if strings.HasPrefix(last, "{") {
+		weight, err = weightSpec(last)
+		if err != nil {
+			return nil, false, "", err
+		}
+		fields = fields[:len(fields)-1]
+	}
+	return fields, false, weight, nil
+}
+
+//Returns the content of a {w} instance weight specification
+func weightSpec(s string) (string, error) {
+	if !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
+		return "", fmt.Errorf("bad instance weight '%s'", s)
+	}
+	return strings.TrimSpace(s[1 : len(s)-1]), nil
+}
+
+//Quotes a name or value with single quotes if it would not be read back as is,
+//like weka's Utils.quote. Quotes, backslashes and line breaks are escaped
+func Quote(s string) string {
+	if s != "" && s != ARFF_MISSING && !strings.ContainsAny(s, " ,{}%'\"\\\t\n\r") {
+		return s
+	}
+	var sb strings.Builder
+	sb.WriteByte('\'')
+	for _, c := range s {
+		switch c {
+		case '\\', '\'':
+			sb.WriteByte('\\')
+			sb.WriteRune(c)
+		case '\n':
+			sb.WriteString("\\n")
+		case '\t':
+			sb.WriteString("\\t")
+		case '\r':
+			sb.WriteString("\\r")
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	sb.WriteByte('\'')
+	return sb.String()
+}
@@ -0,0 +1,267 @@
+package data
+
+import (
+	"bytes"
+	"compress/gzip"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestARFFRoundTrip(t *testing.T) {
+	for _, text := range []string{weatherARFF, reviewsARFF} {
+		insts := readTestARFF(t, text)
+		want := arffString(t, insts)
+		read := readTestARFF(t, want)
+		if got := arffString(t, read); got != want {
+			t.Errorf("read dataset differs:\n%s\nwant:\n%s", got, want)
+		}
+		if read.Instance(1).Weight() != insts.Instance(1).Weight() {
+			t.Errorf("weight %v, want %v", read.Instance(1).Weight(), insts.Instance(1).Weight())
+		}
+	}
+	reviews := readTestARFF(t, reviewsARFF)
+	if weight := reviews.Instance(1).Weight(); weight != 2 {
+		t.Errorf("weight of the second review %v, want 2", weight)
+	}
+}
+
+func TestARFFSparseRoundTrip(t *testing.T) {
+	insts := readTestARFF(t, reviewsARFF)
+	options := NewArffOptions()
+	options.Sparse = true
+	var buf bytes.Buffer
+	if err := WriteARFF(&buf, insts, options); err != nil {
+		t.Fatal(err)
+	}
+	read := readTestARFF(t, buf.String())
+	if !read.Instance(0).IsSparse() {
+		t.Errorf("sparse ARFF read as dense instances")
+	}
+	if got, want := arffString(t, read), arffString(t, insts); got != want {
+		t.Errorf("read dataset differs:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestReadARFFSyntax(t *testing.T) {
+	text := `% a comment before the header
+@RELATION 'survey results' % trailing comment
+@Attribute 'first name' string
+@attribute Age NUMERIC
+@attribute "Favourite Colour" {Red,'light blue','50% grey'}
+@attribute note string
+@DATA
+% comment between rows
+'Ann Lee',31,Red,'it\'s \"fine\"' % trailing comment
+Bob,?,'light blue','?'
+{0 Eve,2 '50% grey'},{0.5}
+{}
+Dan,40,?,"tab\there",{3}
+`
+	insts := readTestARFF(t, text)
+	if insts.DatasetName() != "survey results" {
+		t.Errorf("relation %q", insts.DatasetName())
+	}
+	names := make([]string, 0)
+	for _, attr := range insts.Attributes() {
+		names = append(names, attr.Name())
+	}
+	if got := strings.Join(names, "|"); got != "first name|Age|Favourite Colour|note" {
+		t.Errorf("attribute names %q", got)
+	}
+	colour := insts.Attribute(2)
+	if got := strings.Join(colour.Values(), "|"); got != "Red|light blue|50% grey" {
+		t.Errorf("nominal values %q", got)
+	}
+	if len(insts.Instances()) != 5 {
+		t.Fatalf("read %d instances, want 5", len(insts.Instances()))
+	}
+	first, second, sparse, empty := insts.Instance(0), insts.Instance(1), insts.Instance(2), insts.Instance(3)
+	if name, note := stringValue(insts, 0, 0), stringValue(insts, 0, 3); name != "Ann Lee" || note != `it's "fine"` {
+		t.Errorf("first instance has name %q and note %q", name, note)
+	}
+	if first.Value(1) != 31 || first.Value(2) != 0 {
+		t.Errorf("first instance values %v, %v", first.Value(1), first.Value(2))
+	}
+	//a quoted ? is a value, not missing
+	if !math.IsNaN(second.Value(1)) || math.IsNaN(second.Value(3)) || stringValue(insts, 1, 3) != "?" {
+		t.Errorf("second instance age %v and note %v", second.Value(1), second.Value(3))
+	}
+	if !sparse.IsSparse() || sparse.Weight() != 0.5 || stringValue(insts, 2, 0) != "Eve" || sparse.Value(1) != 0 || sparse.Value(2) != 2 {
+		t.Errorf("sparse instance %v with weight %v", sparse, sparse.Weight())
+	}
+	if !empty.IsSparse() || empty.NumValues() != 0 || empty.Weight() != 1 {
+		t.Errorf("empty sparse instance %v", empty)
+	}
+	if last := insts.Instance(4); last.Weight() != 3 || !math.IsNaN(last.Value(2)) || stringValue(insts, 4, 3) != "tab\there" {
+		t.Errorf("last instance %v with weight %v", last, last.Weight())
+	}
+}
+
+func TestReadARFFErrors(t *testing.T) {
+	header := "@relation r\n@attribute a numeric\n@attribute b {x,y}\n@data\n"
+	for _, text := range []string{
+		header + "'1,x\n",
+		header + "{0 1,1 x\n",
+		header + "1,x,{heavy}\n",
+		header + "1,z\n",
+		header + "1\n",
+		header + "{5 1}\n",
+		"@relation r\n@attribute 'a numeric\n@data\n",
+		"@relation r\n@attribute a {x,y\n@data\n",
+		"@relation r\n@attribute a numeric\n",
+	} {
+		if _, err := ReadARFF(strings.NewReader(text)); err == nil {
+			t.Errorf("ReadARFF read\n%s", text)
+		}
+	}
+}
+
+func TestReadARFFReentrant(t *testing.T) {
+	texts := []string{weatherARFF, reviewsARFF}
+	want := []string{arffString(t, readTestARFF(t, weatherARFF)), arffString(t, readTestARFF(t, reviewsARFF))}
+	results := make([]string, 20)
+	var wg sync.WaitGroup
+	for k := range results {
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			insts, err := ReadARFF(strings.NewReader(texts[k%2]))
+			if err != nil {
+				results[k] = err.Error()
+				return
+			}
+			insts.SetClassIndex(len(insts.Attributes()) - 1)
+			var buf bytes.Buffer
+			if err := WriteARFF(&buf, insts, NewArffOptions()); err != nil {
+				results[k] = err.Error()
+				return
+			}
+			results[k] = buf.String()
+		}(k)
+	}
+	wg.Wait()
+	for k, got := range results {
+		if got != want[k%2] {
+			t.Errorf("read %d gave\n%s\nwant\n%s", k, got, want[k%2])
+		}
+	}
+}
+
+func TestReadARFFSources(t *testing.T) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write([]byte(reviewsARFF))
+	writer.Close()
+	reader, err := gzip.NewReader(&compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromGzip, err := ReadARFF(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromGzip.SetClassIndex(3)
+	if got, want := arffString(t, fromGzip), arffString(t, readTestARFF(t, reviewsARFF)); got != want {
+		t.Errorf("gzip stream read as\n%s\nwant\n%s", got, want)
+	}
+	//files parsed one after the other do not mix their headers
+	dir := t.TempDir()
+	for _, name := range []string{"weather", "reviews"} {
+		text := map[string]string{"weather": weatherARFF, "reviews": reviewsARFF}[name]
+		path := filepath.Join(dir, name+".arff")
+		if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+		insts := NewInstancesWithClassIndex(-1)
+		if err := insts.ParseFile(path); err != nil {
+			t.Fatal(err)
+		}
+		if insts.DatasetName() != name || arffString(t, insts) != arffString(t, readTestARFF(t, text)) {
+			t.Errorf("%s read as\n%s", name, arffString(t, insts))
+		}
+	}
+	//the class index set before reading is kept
+	insts := NewInstancesWithClassIndex(0)
+	if err := insts.Read(strings.NewReader(weatherARFF)); err != nil || insts.ClassIndex() != 0 {
+		t.Errorf("Read gave class %d and error %v", insts.ClassIndex(), err)
+	}
+}
+
+func TestReadARFFParseError(t *testing.T) {
+	_, err := ReadARFF(strings.NewReader("@relation r\n@attribute a numeric\n@data\n1\n% comment\nx\n"))
+	parseErr, ok := err.(*ParseError)
+	if !ok || parseErr.Line != 6 {
+		t.Errorf("error %v, want a *ParseError at line 6", err)
+	}
+}
+
+const writerARFF = `@relation 'my data'
+@attribute 'a b' numeric
+@attribute c {x,'y z'}
+@attribute s string
+@attribute d date
+@data
+1.23456,'y z','it\'s, ok',2024-01-02T03:04:05,{2}
+0,x,?,?
+`
+
+func TestWriteARFFFormats(t *testing.T) {
+	insts := readTestARFF(t, writerARFF)
+	//a stored zero is omitted in sparse output, but not the first string
+	explicit := NewSparseInstance(1.0, []float64{0, 0}, []int{0, 2}, 4)
+	insts.SetInstances(append(insts.Instances(), explicit))
+	header := "@relation 'my data'\n\n@attribute 'a b' numeric\n@attribute c {x,'y z'}\n@attribute s string\n" +
+		"@attribute d date \"yyyy-MM-dd'T'HH:mm:ss\"\n\n@data\n"
+	tests := []struct {
+		sparse, weights bool
+		data            string
+	}{
+		{false, true, "1.23,'y z','it\\'s, ok',2024-01-02T03:04:05,{2}\n0,x,?,?\n0,x,'it\\'s, ok',1970-01-01T00:00:00\n"},
+		{true, true, "{0 1.23,1 'y z',2 'it\\'s, ok',3 2024-01-02T03:04:05},{2}\n{2 ?,3 ?}\n{2 'it\\'s, ok'}\n"},
+		{true, false, "{0 1.23,1 'y z',2 'it\\'s, ok',3 2024-01-02T03:04:05}\n{2 ?,3 ?}\n{2 'it\\'s, ok'}\n"},
+	}
+	for _, test := range tests {
+		options := NewArffOptions()
+		options.Sparse = test.sparse
+		options.Weights = test.weights
+		options.MaxDecimalPlaces = 2
+		var buf bytes.Buffer
+		if err := WriteARFF(&buf, insts, options); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != header+test.data {
+			t.Errorf("sparse %v, weights %v: wrote\n%s\nwant\n%s", test.sparse, test.weights, buf.String(), header+test.data)
+		}
+	}
+}
+
+func TestArffSaverIncremental(t *testing.T) {
+	insts := readTestARFF(t, writerARFF)
+	var buf bytes.Buffer
+	saver := NewArffSaver(&buf, insts, NewArffOptions())
+	if err := saver.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(buf.String(), "@data\n") {
+		t.Errorf("Flush without instances wrote\n%s", buf.String())
+	}
+	header := buf.Len()
+	if err := saver.WriteInstance(insts.Instance(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := saver.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if rest := buf.String()[header:]; rest != "0,x,?,?\n" {
+		t.Errorf("the header was written again or the row is wrong:\n%s", rest)
+	}
+	//values out of range of the header are reported
+	bad := NewDenseInstance(1.0, []float64{0, 5, 0, 0})
+	if err := saver.WriteInstance(bad); err == nil {
+		t.Errorf("wrote a nominal value out of range")
+	}
+}
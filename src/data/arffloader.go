@@ -0,0 +1,151 @@
+package data
+
+import (
+	"io"
+)
+
+//Reads an ARFF dataset incrementally, like weka's ArffLoader: the header is
+//read first and then the instances are returned one at a time, so datasets
+//bigger than the memory can be processed. Usage:
+//
+//	loader := data.NewArffLoader(reader)
+//	header, err := loader.Structure()
+//	for loader.Next() {
+//		instance := loader.Instance()
+//		...
+//	}
+//	err = loader.Err()
+type ArffLoader struct {
+	reader io.Reader
+	arff *arffReader
+	//Index of the class attribute, -1 for none
+	classIndex int
+	//Whether the values of string and relational attributes are kept in the
+	//header, by default only the ones of the current instance are
+	retainStringValues bool
+	//The last instance read
+	current Instance
+	err error
+}
+
+func NewArffLoader(reader io.Reader) ArffLoader {
+	var loader ArffLoader
+	loader.reader = reader
+	loader.classIndex = -1
+	loader.retainStringValues = false
+	return loader
+}
+
+//Returns the header of the dataset without instances, reading it on the first
+//call. Its attributes are a copy, later changes of the loader do not affect it
+func (l *ArffLoader) Structure() (Instances, error) {
+	structure, err := l.structure()
+	attrs := make([]Attribute, len(structure.attributes))
+	for i := range structure.attributes {
+		attrs[i] = structure.attributes[i].Copy()
+	}
+	structure.attributes = attrs
+	return structure, err
+}
+
+//Returns the header of the dataset sharing its attributes with the loader, so
+//it receives the values of string and relational attributes read
+func (l *ArffLoader) structure() (Instances, error) {
+	if l.arff == nil {
+		l.arff = newArffReader(l.reader, l.classIndex)
+		l.err = l.arff.readHeader()
+	}
+	structure := NewInstancesWithClassIndex(l.arff.header.classIndex)
+	structure.datasetName = l.arff.header.datasetName
+	structure.attributes = l.arff.header.attributes
+	return structure, l.err
+}
+
+//Reads the next instance, it returns false at the end of the data or after an
+//error, which is reported by Err
+func (l *ArffLoader) Next() bool {
+	if _, err := l.structure(); err != nil {
+		return false
+	}
+	if !l.retainStringValues {
+		l.clearStringValues()
+	}
+	instance, ok, err := l.arff.readInstance()
+	if err != nil {
+		l.err = err
+		return false
+	}
+	l.current = instance
+	return ok
+}
+
+//Removes the values of string and relational attributes added for the
+//previous instances
+func (l *ArffLoader) clearStringValues() {
+	attrs := l.arff.header.attributes
+	for i := range attrs {
+		if attrs[i].IsString() {
+			attrs[i].SetValues(make([]string, 0))
+			attrs[i].SetValuesIndexes(make(map[string]int))
+		} else if attrs[i].IsRelational() && attrs[i].relationalValues != nil {
+			*attrs[i].relationalValues = (*attrs[i].relationalValues)[:0]
+		}
+	}
+}
+
+//Reads all the remaining instances, the header is read if it was not
+func (l *ArffLoader) DataSet() (Instances, error) {
+	dataset, err := l.structure()
+	if err != nil {
+		return dataset, err
+	}
+	l.retainStringValues = true
+	for l.Next() {
+		dataset.instances = append(dataset.instances, l.current)
+		if hasMissingValue(l.current) {
+			dataset.hasMissing = true
+		}
+	}
+	dataset.shareHeader()
+	return dataset, l.err
+}
+
+//Sets methods
+
+//Sets the index of the class attribute, must be called before the header is
+//read
+func (l *ArffLoader) SetClassIndex(classIndex int) {
+	l.classIndex = classIndex
+}
+
+func (l *ArffLoader) SetRetainStringValues(retain bool) {
+	l.retainStringValues = retain
+}
+
+//Gets methods
+
+//Returns the last instance read by Next
+func (l *ArffLoader) Instance() Instance {
+	return l.current
+}
+
+//Returns the first error found while reading, nil at the end of the data
+func (l *ArffLoader) Err() error {
+	return l.err
+}
+
+//Returns the number of the last line read
+func (l *ArffLoader) LineNumber() int {
+	if l.arff == nil {
+		return 0
+	}
+	return l.arff.lineNum
+}
+
+func (l *ArffLoader) ClassIndex() int {
+	return l.classIndex
+}
+
+func (l *ArffLoader) RetainStringValues() bool {
+	return l.retainStringValues
+}
@@ -0,0 +1,86 @@
+package data
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestArffLoaderMatchesReadARFF(t *testing.T) {
+	loader := NewArffLoader(strings.NewReader(reviewsARFF))
+	loader.SetClassIndex(3)
+	loader.SetRetainStringValues(true)
+	structure, err := loader.Structure()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if structure.DatasetName() != "reviews" || len(structure.Attributes()) != 4 || structure.ClassIndex() != 3 || len(structure.Instances()) != 0 {
+		t.Fatalf("structure %q with %d attributes, class %d and %d instances", structure.DatasetName(), len(structure.Attributes()), structure.ClassIndex(), len(structure.Instances()))
+	}
+	instances := make([]Instance, 0)
+	for loader.Next() {
+		instances = append(instances, loader.Instance())
+	}
+	if err := loader.Err(); err != nil {
+		t.Fatal(err)
+	}
+	//the structure is a copy, the string values read are not added to it
+	if text := structure.Attribute(0); len(text.Values()) != 0 {
+		t.Errorf("reading changed the structure's string values to %v", text.Values())
+	}
+	read, err := loader.Structure()
+	if err != nil {
+		t.Fatal(err)
+	}
+	read.SetInstances(instances)
+	if got, want := arffString(t, read), arffString(t, readTestARFF(t, reviewsARFF)); got != want {
+		t.Errorf("loader read\n%s\nReadARFF read\n%s", got, want)
+	}
+	if loader.Next() || loader.Err() != nil {
+		t.Errorf("Next after the end returned true or error %v", loader.Err())
+	}
+}
+
+func TestArffLoaderStringValues(t *testing.T) {
+	loader := NewArffLoader(strings.NewReader(reviewsARFF))
+	for i := 0; loader.Next(); i++ {
+		structure, err := loader.Structure()
+		if err != nil {
+			t.Fatal(err)
+		}
+		//only the values of the current instance are kept
+		text := structure.Attribute(0)
+		want := 1
+		if i == 2 {
+			want = 0
+		}
+		if len(text.Values()) != want {
+			t.Errorf("instance %d: string values %v, want %d", i, text.Values(), want)
+		}
+	}
+	if err := loader.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestArffLoaderErr(t *testing.T) {
+	text := "@relation r\n@attribute a numeric\n@attribute b {x,y}\n@data\n1,x\n2,z\n3,y\n"
+	_, readErr := ReadARFF(strings.NewReader(text))
+	if readErr == nil {
+		t.Fatal("ReadARFF read an undeclared nominal value")
+	}
+	loader := NewArffLoader(strings.NewReader(text))
+	count := 0
+	for loader.Next() {
+		count++
+	}
+	if count != 1 || loader.Err() == nil || loader.Err().Error() != readErr.Error() {
+		t.Errorf("loader read %d instances and stopped with %v, want 1 and %v", count, loader.Err(), readErr)
+	}
+	if loader.LineNumber() != 6 {
+		t.Errorf("error at line %d, want 6", loader.LineNumber())
+	}
+	bad := NewArffLoader(strings.NewReader("@relation r\n@attribute a wrongtype\n@data\n"))
+	if _, err := bad.Structure(); err == nil || bad.Next() || bad.Err() == nil {
+		t.Errorf("bad header gave error %v", err)
+	}
+}
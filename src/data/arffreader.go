@@ -0,0 +1,140 @@
+package data
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+//Maximum length of a line, sparse rows of big word vectors can be long
+const maxLineLength = 1 << 30
+
+//Reads an ARFF dataset from a stream in a single pass. All the parsing state
+//lives in the reader, so any number of datasets can be read concurrently
+type arffReader struct {
+	scanner *bufio.Scanner
+	//Number of the last line read
+	lineNum int
+	//The dataset being read, holds the header once it has been read
+	header Instances
+}
+
+func newArffReader(reader io.Reader, classIndex int) *arffReader {
+	ar := new(arffReader)
+	ar.scanner = bufio.NewScanner(reader)
+	ar.scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+	ar.header = NewInstancesWithClassIndex(classIndex)
+	ar.header.attributes = make([]Attribute, 0)
+	return ar
+}
+
+//Reads a dataset in ARFF format from reader, e.g. a file, an HTTP request body
+//or a gzip.Reader. No class attribute is set, use SetClassIndex afterwards.
+//Errors in the input are returned as *ParseError
+func ReadARFF(reader io.Reader) (Instances, error) {
+	inst := NewInstancesWithClassIndex(-1)
+	err := inst.Read(reader)
+	return inst, err
+}
+
+//Reads the ARFF dataset from reader into inst, replacing its header and
+//instances, the class index set in inst is kept
+func (inst *Instances) Read(reader io.Reader) error {
+	ar := newArffReader(reader, inst.classIndex)
+	if err := ar.readHeader(); err != nil {
+		return err
+	}
+	insts := make([]Instance, 0)
+	for {
+		instance, ok, err := ar.readInstance()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		insts = append(insts, instance)
+	}
+	*inst = ar.header
+	inst.instances = insts
+	inst.shareHeader()
+	return nil
+}
+
+//Reads the header up to the @data declaration
+func (ar *arffReader) readHeader() error {
+	header := &ar.header
+	//relational attributes being declared, their inner attributes go to the
+	//last one until its @end
+	relational := make([]Attribute, 0)
+	for ar.scanner.Scan() {
+		ar.lineNum++
+		line := strings.TrimSpace(stripComment(ar.scanner.Text()))
+		if line == "" {
+			continue
+		}
+		if isDeclaration(line, ARFF_DATA) {
+			if len(relational) > 0 {
+				return newParseError(ar.lineNum, "missing @end for relational attribute '%s'", relational[len(relational)-1].Name())
+			}
+			if len(header.attributes) == 0 {
+				return newParseError(ar.lineNum, "no attributes declared")
+			}
+			if header.classIndex >= len(header.attributes) {
+				return newParseError(0, "class index %d is out of range, there are %d attributes", header.classIndex, len(header.attributes))
+			}
+			return nil
+		}
+		if isDeclaration(line, ARFF_RELATION) && len(relational) == 0 {
+			tokens, err := headerTokens(line)
+			if err != nil || len(tokens) < 2 {
+				return newParseError(ar.lineNum, "bad @relation declaration '%s'", line)
+			}
+			//names with spaces written without quotes are joined back
+			header.SetDatasetName(strings.Join(tokens[1:], " "))
+		} else if isDeclaration(line, ARFF_ATTRIBUTE) {
+			parent := header
+			if len(relational) > 0 {
+				parent = relational[len(relational)-1].Relation()
+			}
+			attr, err := parent.parseAttribute(line, len(parent.attributes))
+			if err != nil {
+				return newParseError(ar.lineNum, "bad @attribute declaration: %s", err.Error())
+			}
+			parent.attributes = append(parent.attributes, attr)
+			if attr.IsRelational() {
+				relational = append(relational, attr)
+			}
+		} else if isDeclaration(line, ARFF_END) && len(relational) > 0 {
+			tokens, err := headerTokens(line)
+			current := relational[len(relational)-1]
+			if err != nil || len(tokens) != 2 || tokens[1] != current.Name() {
+				return newParseError(ar.lineNum, "expected '@end %s'", current.Name())
+			}
+			relational = relational[:len(relational)-1]
+		} else {
+			return newParseError(ar.lineNum, "unexpected line in the header: '%s'", line)
+		}
+	}
+	if err := ar.scanner.Err(); err != nil {
+		return err
+	}
+	return newParseError(ar.lineNum, "missing @data declaration")
+}
+
+//Reads the next instance, ok is false at the end of the input
+func (ar *arffReader) readInstance() (instance Instance, ok bool, err error) {
+	for ar.scanner.Scan() {
+		ar.lineNum++
+		line := strings.TrimSpace(stripComment(ar.scanner.Text()))
+		if len(line) == 0 {
+			continue
+		}
+		instance, err := ar.header.parseInstance(line)
+		if err != nil {
+			return instance, false, newParseError(ar.lineNum, "malformed instance: %s", err.Error())
+		}
+		return instance, true, nil
+	}
+	return instance, false, ar.scanner.Err()
+}
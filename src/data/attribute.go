@@ -2,6 +2,8 @@ package data
 
 import (
 	"fmt"
+	"strings"
+	"time"
 )
 
 const (
@@ -12,14 +14,20 @@ const (
 	STRING = 4
 	INPUT = 5
 	OUTPUT = 6
+	DATE = 7
+	RELATIONAL = 8
 )
+
+//Default format of date attributes, as in weka
+const DEFAULT_DATE_FORMAT = "yyyy-MM-dd'T'HH:mm:ss"
+
 //it can be implemented as constants
 type Attribute struct {
 	//Constants
-	Arff_Attribute, Arff_String, Arff_Integer, Arff_Real, Arff_Numeric string
+	Arff_Attribute, Arff_String, Arff_Integer, Arff_Real, Arff_Numeric, Arff_Date, Arff_Relational, Arff_End string
 	//Attribute type
 	attr_type int
-	//If attribute is input or output, 0 input, 1 output
+	//If attribute is INPUT or OUTPUT
 	direction int
 	//Values that a nominal or string attribute can hold
 	values []string
@@ -35,6 +43,13 @@ type Attribute struct {
 	weight float64
 	//Attribute index
 	index int
+	//Date format pattern (java's SimpleDateFormat syntax) and its go layout
+	dateFormat, dateLayout string
+	//Header of the instances held by a relational attribute
+	relation *Instances
+	//Bags of instances of a relational attribute, the instances' values
+	//are indexes into this slice
+	relationalValues *[]Instances
 }
 
 //Attribute's struct constructor
@@ -46,8 +61,12 @@ func NewAttribute() Attribute {
 	attr.Arff_Numeric = "numeric"
 	attr.Arff_Real = "real"
 	attr.Arff_String = "string"
+	attr.Arff_Date = "date"
+	attr.Arff_Relational = "relational"
+	attr.Arff_End = "@end"
 	attr.valuesIndexes = make(map[string]int,0)
 	attr.values = make([]string, 0)
+	attr.weight = 1.0
 	return attr
 }
 
@@ -81,6 +100,200 @@ func (a *Attribute) IsNominal() bool {
 	return a.attr_type == NOMINAL
 }
 
+func (a *Attribute) IsDate() bool {
+	return a.attr_type == DATE
+}
+
+func (a *Attribute) IsRelational() bool {
+	return a.attr_type == RELATIONAL
+}
+
+//Date and numeric attributes hold real values
+func (a *Attribute) IsNumeric() bool {
+	return a.attr_type == NUMERIC || a.attr_type == DATE
+}
+
+//Parses a date with the attribute's format and returns the milliseconds
+//since the epoch, dates without a time zone are taken as UTC
+func (a *Attribute) ParseDate(value string) (float64, error) {
+	if !a.IsDate() {
+		return 0, fmt.Errorf("Attribute '%s' is not a date attribute", a.name)
+	}
+	t, err := time.ParseInLocation(a.dateLayout, value, time.UTC)
+	if err != nil {
+		return 0, fmt.Errorf("Date '%s' does not match the format '%s' of '%s'", value, a.dateFormat, a.name)
+	}
+	return float64(t.UnixNano() / int64(time.Millisecond)), nil
+}
+
+//Formats milliseconds since the epoch with the attribute's date format
+func (a *Attribute) FormatDate(millis float64) string {
+	t := time.Unix(0, int64(millis)*int64(time.Millisecond)).UTC()
+	return t.Format(a.dateLayout)
+}
+
+//Adds a bag of instances to a relational attribute and returns its index
+func (a *Attribute) AddRelation(bag Instances) int {
+	if a.relationalValues == nil {
+		values := make([]Instances, 0)
+		a.relationalValues = &values
+	}
+	*a.relationalValues = append(*a.relationalValues, bag)
+	return len(*a.relationalValues) - 1
+}
+
+//Returns the bag of instances with the given index of a relational attribute
+func (a *Attribute) RelationValue(idx int) Instances {
+	return (*a.relationalValues)[idx]
+}
+
+//Returns a copy of the attribute that does not share its values with it.
+//The header of a relational attribute is copied too, its bags are shared
+func (a *Attribute) Copy() Attribute {
+	attr := *a
+	attr.values = make([]string, len(a.values))
+	copy(attr.values, a.values)
+	attr.valuesIndexes = make(map[string]int, len(a.valuesIndexes))
+	for value, index := range a.valuesIndexes {
+		attr.valuesIndexes[value] = index
+	}
+	if a.relation != nil {
+		relation := NewInstancesWithClassIndex(a.relation.ClassIndex())
+		relation.SetDatasetName(a.relation.DatasetName())
+		inner := make([]Attribute, len(a.relation.Attributes()))
+		for i := range inner {
+			inner[i] = a.relation.Attribute(i).Copy()
+		}
+		relation.SetAttributes(inner)
+		attr.relation = &relation
+	}
+	return attr
+}
+
+//Whether literal text of a date format contains something that go would
+//read as an element of the layout, go layouts can not escape them: digits,
+//'_' (padding of days), month and day names, time zones and AM/PM
+func hasLayoutElement(literal string) bool {
+	if strings.ContainsAny(literal, "0123456789_") {
+		return true
+	}
+	for _, element := range []string{"Jan", "Mon", "MST", "PM", "pm"} {
+		if strings.Contains(literal, element) {
+			return true
+		}
+	}
+	return false
+}
+
+//Translates a java SimpleDateFormat pattern into a go time layout
+func dateLayout(pattern string) (string, error) {
+	var sb strings.Builder
+	i := 0
+	for i < len(pattern) {
+		c := pattern[i]
+		if c == '\'' {
+			//'' is a quote, inside quoted text too
+			if i+1 < len(pattern) && pattern[i+1] == '\'' {
+				sb.WriteByte('\'')
+				i += 2
+				continue
+			}
+			var literal strings.Builder
+			closed := false
+			for i++; i < len(pattern) && !closed; i++ {
+				if pattern[i] != '\'' {
+					literal.WriteByte(pattern[i])
+				} else if i+1 < len(pattern) && pattern[i+1] == '\'' {
+					literal.WriteByte('\'')
+					i++
+				} else {
+					closed = true
+				}
+			}
+			if !closed {
+				return "", fmt.Errorf("unterminated quote in date format '%s'", pattern)
+			}
+			if hasLayoutElement(literal.String()) {
+				return "", fmt.Errorf("literal '%s' in date format '%s' would be read as a date element", literal.String(), pattern)
+			}
+			sb.WriteString(literal.String())
+			continue
+		}
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			if hasLayoutElement(string(c)) {
+				return "", fmt.Errorf("literal '%c' in date format '%s' would be read as a date element", c, pattern)
+			}
+			sb.WriteByte(c)
+			i++
+			continue
+		}
+		n := 1
+		for i+n < len(pattern) && pattern[i+n] == c {
+			n++
+		}
+		var layout string
+		switch c {
+		case 'y':
+			layout = "2006"
+			if n == 2 {
+				layout = "06"
+			}
+		case 'M':
+			layout = map[int]string{1: "1", 2: "01", 3: "Jan"}[n]
+			if n >= 4 {
+				layout = "January"
+			}
+		case 'd':
+			layout = "02"
+			if n == 1 {
+				layout = "2"
+			}
+		case 'H':
+			layout = "15"
+		case 'h':
+			layout = "03"
+			if n == 1 {
+				layout = "3"
+			}
+		case 'm':
+			layout = "04"
+			if n == 1 {
+				layout = "4"
+			}
+		case 's':
+			layout = "05"
+			if n == 1 {
+				layout = "5"
+			}
+		case 'S':
+			//go only reads fractions of a second after a period or a comma,
+			//elsewhere the zeros would be taken as literal text
+			if written := sb.String(); !strings.HasSuffix(written, ".") && !strings.HasSuffix(written, ",") {
+				return "", fmt.Errorf("milliseconds must follow '.' or ',' in date format '%s'", pattern)
+			}
+			layout = strings.Repeat("0", n)
+		case 'a':
+			layout = "PM"
+		case 'E':
+			layout = "Mon"
+			if n >= 4 {
+				layout = "Monday"
+			}
+		case 'z':
+			layout = "MST"
+		case 'Z':
+			layout = "-0700"
+		case 'X':
+			layout = "Z07:00"
+		default:
+			return "", fmt.Errorf("unsupported letter '%c' in date format '%s'", c, pattern)
+		}
+		sb.WriteString(layout)
+		i += n
+	}
+	return sb.String(), nil
+}
+
 //Sets methods
 
 func(a *Attribute) SetIndex(index int) {
@@ -123,6 +336,23 @@ func (a *Attribute) SetValues(values []string) {
 	a.values = values
 }
 
+//Sets the date format, a java SimpleDateFormat pattern such as the default
+//"yyyy-MM-dd'T'HH:mm:ss"
+func (a *Attribute) SetDateFormat(format string) error {
+	layout, err := dateLayout(format)
+	if err != nil {
+		return err
+	}
+	a.dateFormat = format
+	a.dateLayout = layout
+	return nil
+}
+
+//Sets the header of the instances held by a relational attribute
+func (a *Attribute) SetRelation(relation *Instances) {
+	a.relation = relation
+}
+
 //Gets methods
 
 func (a *Attribute) Index() int {
@@ -163,4 +393,12 @@ func (a *Attribute) Values() []string {
 
 func (a *Attribute) ValuesIndexes() map[string]int {
 	return a.valuesIndexes
+}
+
+func (a *Attribute) DateFormat() string {
+	return a.dateFormat
+}
+
+func (a *Attribute) Relation() *Instances {
+	return a.relation
 }
\ No newline at end of file
@@ -0,0 +1,41 @@
+package data
+
+import "testing"
+
+func TestDateLayout(t *testing.T) {
+	tests := []struct {
+		pattern, layout string
+	}{
+		{"yyyy-MM-dd'T'HH:mm:ss", "2006-01-02T15:04:05"},
+		{"yyyy-MM-dd HH:mm:ss.SSS", "2006-01-02 15:04:05.000"},
+		{"HH:mm:ss,SS", "15:04:05,00"},
+		{"'Day' d 'of' MMMM", "Day 2 of January"},
+		{"hh 'o''clock' a", "03 o'clock PM"},
+	}
+	for _, test := range tests {
+		layout, err := dateLayout(test.pattern)
+		if err != nil || layout != test.layout {
+			t.Errorf("dateLayout(%q) = %q, %v, want %q", test.pattern, layout, err, test.layout)
+		}
+	}
+	//go would read the digits, '_' and names in the literals as elements of
+	//the layout
+	for _, pattern := range []string{"HH:mm:ss SSS", "ssSSS", "SSS", "'Day 1' HH:mm", "yyyy'Jan'dd", "HH:mm 'PM'",
+		"'Monday' dd", "HH:mm 'MST'", "yyyy_dd", "yyyy1MM", "yyyy 'open"} {
+		if _, err := dateLayout(pattern); err == nil {
+			t.Errorf("dateLayout(%q) did not fail", pattern)
+		}
+	}
+	attr := NewAttribute()
+	attr.SetType(DATE)
+	if err := attr.SetDateFormat("yyyy-MM-dd HH:mm:ss.SSS"); err != nil {
+		t.Fatal(err)
+	}
+	date, err := attr.ParseDate("2001-02-03 04:05:06.789")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := attr.FormatDate(date); got != "2001-02-03 04:05:06.789" {
+		t.Errorf("FormatDate = %q", got)
+	}
+}
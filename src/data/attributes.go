@@ -1,8 +1,5 @@
 package data
 
-import (
-
-)
 
 type Attributes struct {
 	//List of all attributes
@@ -31,6 +28,55 @@ func NewAttributes() Attributes {
 	return attrs
 }
 
+//Creates the attributes' info of a dataset header, the class attribute is the
+//output attribute and the rest are inputs. HasMissing is left false, see
+//UpdateMissing
+func NewAttributesWithHeader(relationName string, attrs []Attribute, classIndex int) Attributes {
+	outputs := []int{}
+	if classIndex >= 0 {
+		outputs = []int{classIndex}
+	}
+	return newAttributesWithOutputs(relationName, attrs, outputs)
+}
+
+//Creates the attributes' info of a dataset header with the given output
+//attributes, the rest are inputs
+func newAttributesWithOutputs(relationName string, attrs []Attribute, outputs []int) Attributes {
+	info := NewAttributes()
+	info.relationName = relationName
+	info.attributes = attrs
+	info.totalAttrs = len(attrs)
+	isOutput := make(map[int]bool, len(outputs))
+	for _, idx := range outputs {
+		isOutput[idx] = true
+	}
+	for i, attr := range attrs {
+		if isOutput[i] {
+			info.outputAttrs = append(info.outputAttrs, attr)
+		} else {
+			info.inputAttrs = append(info.inputAttrs, attr)
+		}
+		switch attr.Type() {
+		case NOMINAL:
+			info.hasNominal = true
+		case INTEGER:
+			info.hasInteger = true
+		case REAL, NUMERIC:
+			info.hasReal = true
+		case STRING:
+			info.hasString = true
+		}
+	}
+	return info
+}
+
+//Sets HasMissing if the instance has a missing value
+func (a *Attributes) UpdateMissing(instance Instance) {
+	if !a.hasMissing && hasMissingValue(instance) {
+		a.hasMissing = true
+	}
+}
+
 //Add a new attribute and depending of it's direction adds it to inputAttrs or outputAttrs
 //if is an input attribute or output attribute respectively
 func (a *Attributes) AddAttribute(at Attribute) {
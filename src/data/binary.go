@@ -0,0 +1,778 @@
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"unsafe"
+)
+
+//Binary format of datasets, much faster to load than ARFF. The file starts
+//with BINARY_MAGIC and the version, followed by the header and the rows:
+//
+//	header:    relation name, class index, number of attributes, attributes
+//	attribute: type, name, weight, direction and, depending on the type, the
+//	           bounds, the values, the date format or the relational header
+//	           followed by the bags
+//	rows:      number of rows, then for each: weight, dense/sparse flag,
+//	           number of values, indexes (sparse only, delta coded), values
+//
+//Integers are varints, numbers little endian float64 and strings are length
+//prefixed. The file ends with the CRC-32 of all the preceding bytes.
+//
+//Files of version BINARY_VERSION_CSR store the rows as the arrays of a
+//CSRMatrix instead, written by SaveBinaryCSR so that MapBinary can use them
+//in place:
+//
+//	rows:      number of rows, number of values, zero bytes up to a multiple
+//	           of 8 from the start of the file, then the weights (float64),
+//	           row offsets (int64, one more than the rows), values (float64)
+//	           and attribute indexes (int32), all little endian
+const (
+	BINARY_MAGIC       = "MACD"
+	BINARY_VERSION     = 1
+	BINARY_VERSION_CSR = 2
+)
+
+const (
+	binaryDense  = 0
+	binarySparse = 1
+)
+
+//Writes binary data while computing its checksum
+type binaryEncoder struct {
+	writer *bufio.Writer
+	crc hash.Hash32
+	buf [binary.MaxVarintLen64]byte
+	//Number of bytes written
+	n int64
+}
+
+func (e *binaryEncoder) write(p []byte) {
+	e.writer.Write(p)
+	e.crc.Write(p)
+	e.n += int64(len(p))
+}
+
+func (e *binaryEncoder) writeUvarint(x uint64) {
+	n := binary.PutUvarint(e.buf[:], x)
+	e.write(e.buf[:n])
+}
+
+func (e *binaryEncoder) writeVarint(x int64) {
+	n := binary.PutVarint(e.buf[:], x)
+	e.write(e.buf[:n])
+}
+
+func (e *binaryEncoder) writeFloat(x float64) {
+	binary.LittleEndian.PutUint64(e.buf[:8], math.Float64bits(x))
+	e.write(e.buf[:8])
+}
+
+func (e *binaryEncoder) writeUint64(x uint64) {
+	binary.LittleEndian.PutUint64(e.buf[:8], x)
+	e.write(e.buf[:8])
+}
+
+func (e *binaryEncoder) writeUint32(x uint32) {
+	binary.LittleEndian.PutUint32(e.buf[:4], x)
+	e.write(e.buf[:4])
+}
+
+//Writes zero bytes up to a multiple of 8 from the start
+func (e *binaryEncoder) align() {
+	var zeros [8]byte
+	e.write(zeros[:binaryPadding(e.n)])
+}
+
+//Returns the number of bytes needed to reach a multiple of 8 from offset
+func binaryPadding(offset int64) int {
+	return int((8 - offset%8) % 8)
+}
+
+func (e *binaryEncoder) writeString(s string) {
+	e.writeUvarint(uint64(len(s)))
+	e.write([]byte(s))
+}
+
+func (e *binaryEncoder) writeBool(b bool) {
+	if b {
+		e.write([]byte{1})
+	} else {
+		e.write([]byte{0})
+	}
+}
+
+//Writes the dataset in binary format
+func WriteBinary(writer io.Writer, instances Instances) error {
+	e := &binaryEncoder{writer: bufio.NewWriter(writer), crc: crc32.NewIEEE()}
+	e.write([]byte(BINARY_MAGIC))
+	e.writeUvarint(BINARY_VERSION)
+	if err := e.writeHeader(instances); err != nil {
+		return err
+	}
+	if err := e.writeRows(instances); err != nil {
+		return err
+	}
+	return e.finish()
+}
+
+//Writes the dataset in binary format with the rows as the arrays of a
+//CSRMatrix, which MapBinary can map in memory. Zero values are not stored, so
+//the instances are read back as sparse ones
+func WriteBinaryCSR(writer io.Writer, instances Instances) error {
+	e := &binaryEncoder{writer: bufio.NewWriter(writer), crc: crc32.NewIEEE()}
+	e.write([]byte(BINARY_MAGIC))
+	e.writeUvarint(BINARY_VERSION_CSR)
+	if err := e.writeHeader(instances); err != nil {
+		return err
+	}
+	if err := e.writeCSR(instances); err != nil {
+		return err
+	}
+	return e.finish()
+}
+
+//Writes the checksum and flushes the output
+func (e *binaryEncoder) finish() error {
+	var sum [4]byte
+	binary.LittleEndian.PutUint32(sum[:], e.crc.Sum32())
+	e.writer.Write(sum[:])
+	return e.writer.Flush()
+}
+
+//Writes the dataset to a file in binary format
+func SaveBinary(path string, instances Instances) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteBinary(file, instances); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+//Writes the dataset to a file in binary format with the rows as the arrays of
+//a CSRMatrix, see WriteBinaryCSR
+func SaveBinaryCSR(path string, instances Instances) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteBinaryCSR(file, instances); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (e *binaryEncoder) writeHeader(instances Instances) error {
+	e.writeString(instances.DatasetName())
+	e.writeVarint(int64(instances.ClassIndex()))
+	e.writeUvarint(uint64(len(instances.Attributes())))
+	for _, attr := range instances.Attributes() {
+		e.writeUvarint(uint64(attr.Type()))
+		e.writeString(attr.Name())
+		e.writeFloat(attr.Weight())
+		e.writeUvarint(uint64(attr.Direction()))
+		switch attr.Type() {
+		case NUMERIC:
+			e.writeBool(attr.HasFixedBounds())
+			e.writeFloat(attr.Min())
+			e.writeFloat(attr.Max())
+		case NOMINAL, STRING:
+			e.writeUvarint(uint64(len(attr.Values())))
+			for _, value := range attr.Values() {
+				e.writeString(value)
+			}
+		case DATE:
+			e.writeString(attr.DateFormat())
+		case RELATIONAL:
+			if err := e.writeHeader(*attr.Relation()); err != nil {
+				return err
+			}
+			bags := 0
+			if attr.relationalValues != nil {
+				bags = len(*attr.relationalValues)
+			}
+			e.writeUvarint(uint64(bags))
+			for i := 0; i < bags; i++ {
+				if err := e.writeRows(attr.RelationValue(i)); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("binary: attribute '%s' of type %d is not supported", attr.Name(), attr.Type())
+		}
+	}
+	return nil
+}
+
+func (e *binaryEncoder) writeRows(instances Instances) error {
+	e.writeUvarint(uint64(len(instances.Instances())))
+	for _, instance := range instances.Instances() {
+		e.writeFloat(instance.Weight())
+		if instance.IsSparse() {
+			e.writeUvarint(binarySparse)
+		} else {
+			e.writeUvarint(binaryDense)
+		}
+		e.writeUvarint(uint64(instance.NumValues()))
+		if instance.IsSparse() {
+			last := -1
+			for j := 0; j < instance.NumValues(); j++ {
+				idx := instance.Index(j)
+				if idx <= last {
+					return fmt.Errorf("binary: sparse indexes must be in ascending order, found %d after %d", idx, last)
+				}
+				e.writeUvarint(uint64(idx - last))
+				last = idx
+			}
+		}
+		for j := 0; j < instance.NumValues(); j++ {
+			e.writeFloat(instance.ValueSparse(j))
+		}
+	}
+	return nil
+}
+
+func (e *binaryEncoder) writeCSR(instances Instances) error {
+	//the matrix of the dataset is written as it is if its rows are in the
+	//order of the instances and holds their values
+	m := instances.CSR()
+	if m != nil && m.Detached() {
+		m = nil
+	}
+	for r, instance := range instances.Instances() {
+		if m == nil {
+			break
+		}
+		if row, isRow := instance.(*CSRInstance); !isRow || row.matrix != m || row.row != r {
+			m = nil
+		}
+	}
+	if m == nil {
+		var err error
+		if m, err = NewCSRMatrix(instances.Instances(), len(instances.Attributes())); err != nil {
+			return err
+		}
+	}
+	e.writeUvarint(uint64(m.NumRows()))
+	e.writeUvarint(uint64(m.NumNonZero()))
+	e.align()
+	for _, weight := range m.weights {
+		e.writeFloat(weight)
+	}
+	for _, offset := range m.rowPtr {
+		e.writeUint64(uint64(offset))
+	}
+	for _, value := range m.values {
+		e.writeFloat(value)
+	}
+	for _, idx := range m.indices {
+		e.writeUint32(uint32(idx))
+	}
+	return nil
+}
+
+//Reads binary data while computing its checksum
+type binaryDecoder struct {
+	reader *bufio.Reader
+	crc hash.Hash32
+	buf [8]byte
+	//Number of bytes read
+	n int64
+}
+
+func (d *binaryDecoder) ReadByte() (byte, error) {
+	b, err := d.reader.ReadByte()
+	if err == nil {
+		d.buf[0] = b
+		d.crc.Write(d.buf[:1])
+		d.n++
+	}
+	return b, err
+}
+
+func (d *binaryDecoder) read(n int) ([]byte, error) {
+	p := make([]byte, n)
+	if _, err := io.ReadFull(d.reader, p); err != nil {
+		return nil, err
+	}
+	d.crc.Write(p)
+	d.n += int64(n)
+	return p, nil
+}
+
+func (d *binaryDecoder) readUvarint() (uint64, error) {
+	return binary.ReadUvarint(d)
+}
+
+//Reads a length or count, which must fit in an int
+func (d *binaryDecoder) readLength() (int, error) {
+	x, err := binary.ReadUvarint(d)
+	if err == nil && x > math.MaxInt32 {
+		err = fmt.Errorf("binary: bad length %d", x)
+	}
+	return int(x), err
+}
+
+func (d *binaryDecoder) readVarint() (int64, error) {
+	return binary.ReadVarint(d)
+}
+
+func (d *binaryDecoder) readFloat() (float64, error) {
+	x, err := d.readUint64()
+	return math.Float64frombits(x), err
+}
+
+func (d *binaryDecoder) readUint64() (uint64, error) {
+	if _, err := io.ReadFull(d.reader, d.buf[:8]); err != nil {
+		return 0, err
+	}
+	d.crc.Write(d.buf[:8])
+	d.n += 8
+	return binary.LittleEndian.Uint64(d.buf[:8]), nil
+}
+
+func (d *binaryDecoder) readUint32() (uint32, error) {
+	if _, err := io.ReadFull(d.reader, d.buf[:4]); err != nil {
+		return 0, err
+	}
+	d.crc.Write(d.buf[:4])
+	d.n += 4
+	return binary.LittleEndian.Uint32(d.buf[:4]), nil
+}
+
+func (d *binaryDecoder) readString() (string, error) {
+	n, err := d.readLength()
+	if err != nil {
+		return "", err
+	}
+	p, err := d.read(n)
+	return string(p), err
+}
+
+//Reads a dataset written by WriteBinary or WriteBinaryCSR, the class index is
+//the one it was saved with. The instances of the latter are stored in a
+//CSRMatrix
+func ReadBinary(reader io.Reader) (Instances, error) {
+	d := &binaryDecoder{reader: bufio.NewReaderSize(reader, 1<<16), crc: crc32.NewIEEE()}
+	magic, err := d.read(len(BINARY_MAGIC))
+	if err != nil || string(magic) != BINARY_MAGIC {
+		return NewInstancesWithClassIndex(-1), ErrNotBinary
+	}
+	version, err := d.readUvarint()
+	if err != nil {
+		return NewInstancesWithClassIndex(-1), binaryError(err)
+	}
+	if version != BINARY_VERSION && version != BINARY_VERSION_CSR {
+		return NewInstancesWithClassIndex(-1), fmt.Errorf("binary: unsupported version %d, expected %d or %d", version, BINARY_VERSION, BINARY_VERSION_CSR)
+	}
+	insts, err := d.readHeader()
+	if err != nil {
+		return insts, binaryError(err)
+	}
+	if version == BINARY_VERSION_CSR {
+		err = d.readCSR(&insts)
+	} else {
+		err = d.readRows(&insts)
+	}
+	if err != nil {
+		return insts, binaryError(err)
+	}
+	sum := d.crc.Sum32()
+	if _, err := io.ReadFull(d.reader, d.buf[:4]); err != nil {
+		return insts, binaryError(err)
+	}
+	if binary.LittleEndian.Uint32(d.buf[:4]) != sum {
+		return insts, ErrBadChecksum
+	}
+	return insts, nil
+}
+
+//Reads a dataset from a file written by SaveBinary or SaveBinaryCSR
+func LoadBinary(path string) (Instances, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return NewInstancesWithClassIndex(-1), err
+	}
+	defer file.Close()
+	return ReadBinary(file)
+}
+
+//Loads a dataset from a file written by SaveBinaryCSR without copying its
+//rows: the arrays of the CSRMatrix of the instances are the file's content,
+//mapped in memory. The header is read into memory and the whole file is
+//checked. Call Close on the matrix to release the mapping once the dataset is
+//no longer used. On big endian systems the rows are read as by LoadBinary
+func MapBinary(path string) (Instances, error) {
+	if binary.NativeEndian.Uint16([]byte{1, 0}) != 1 {
+		return LoadBinary(path)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return NewInstancesWithClassIndex(-1), err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return NewInstancesWithClassIndex(-1), err
+	}
+	if info.Size() < int64(len(BINARY_MAGIC))+4 || info.Size() > math.MaxInt {
+		return NewInstancesWithClassIndex(-1), ErrNotBinary
+	}
+	content, unmap, err := mapFile(file, int(info.Size()))
+	if err != nil {
+		return NewInstancesWithClassIndex(-1), err
+	}
+	insts, err := mapCSR(content)
+	if err != nil {
+		unmap()
+		return insts, err
+	}
+	insts.csr.unmap = unmap
+	return insts, nil
+}
+
+//Reads the header from the content of a file and makes the instances refer to
+//the arrays in it
+func mapCSR(content []byte) (Instances, error) {
+	body := content[:len(content)-4]
+	if binary.LittleEndian.Uint32(content[len(body):]) != crc32.ChecksumIEEE(body) {
+		if string(content[:len(BINARY_MAGIC)]) != BINARY_MAGIC {
+			return NewInstancesWithClassIndex(-1), ErrNotBinary
+		}
+		return NewInstancesWithClassIndex(-1), ErrBadChecksum
+	}
+	d := &binaryDecoder{reader: bufio.NewReader(bytes.NewReader(body)), crc: crc32.NewIEEE()}
+	magic, err := d.read(len(BINARY_MAGIC))
+	if err != nil || string(magic) != BINARY_MAGIC {
+		return NewInstancesWithClassIndex(-1), ErrNotBinary
+	}
+	version, err := d.readUvarint()
+	if err != nil {
+		return NewInstancesWithClassIndex(-1), binaryError(err)
+	}
+	if version != BINARY_VERSION_CSR {
+		return NewInstancesWithClassIndex(-1), fmt.Errorf("binary: version %d can't be mapped, the file must be written by SaveBinaryCSR", version)
+	}
+	insts, err := d.readHeader()
+	if err != nil {
+		return insts, binaryError(err)
+	}
+	numRows, err := d.readLength()
+	if err != nil {
+		return insts, binaryError(err)
+	}
+	nnz, err := d.readLength()
+	if err != nil {
+		return insts, binaryError(err)
+	}
+	offset := d.n + int64(binaryPadding(d.n))
+	if offset+8*int64(numRows)+8*int64(numRows+1)+12*int64(nnz) != int64(len(body)) {
+		return insts, fmt.Errorf("binary: the size of the rows does not match the file size")
+	}
+	m := new(CSRMatrix)
+	m.numAttributes = len(insts.attributes)
+	//the offsets are multiples of 8 from the start of the mapping, which is
+	//page aligned
+	if numRows > 0 {
+		m.weights = unsafe.Slice((*float64)(unsafe.Pointer(&body[offset])), numRows)
+	}
+	offset += 8 * int64(numRows)
+	m.rowPtr = unsafe.Slice((*int64)(unsafe.Pointer(&body[offset])), numRows+1)
+	offset += 8 * int64(numRows+1)
+	if nnz > 0 {
+		m.values = unsafe.Slice((*float64)(unsafe.Pointer(&body[offset])), nnz)
+		offset += 8 * int64(nnz)
+		m.indices = unsafe.Slice((*int32)(unsafe.Pointer(&body[offset])), nnz)
+	}
+	if err := m.check(insts.attributes); err != nil {
+		return insts, fmt.Errorf("binary: %s", err.Error())
+	}
+	insts.setCSR(m)
+	return insts, nil
+}
+
+//A truncated input is reported as such instead of as io.EOF
+func binaryError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("binary: unexpected end of data")
+	}
+	return err
+}
+
+func (d *binaryDecoder) readHeader() (Instances, error) {
+	insts := NewInstancesWithClassIndex(-1)
+	name, err := d.readString()
+	if err != nil {
+		return insts, err
+	}
+	insts.SetDatasetName(name)
+	classIndex, err := d.readVarint()
+	if err != nil {
+		return insts, err
+	}
+	numAttrs, err := d.readLength()
+	if err != nil {
+		return insts, err
+	}
+	if classIndex < -1 || classIndex >= int64(numAttrs) {
+		return insts, fmt.Errorf("binary: bad class index %d", classIndex)
+	}
+	insts.classIndex = int(classIndex)
+	attrs := make([]Attribute, numAttrs)
+	for i := range attrs {
+		attr := NewAttribute()
+		attr.SetIndex(i)
+		attrType, err := d.readUvarint()
+		if err != nil {
+			return insts, err
+		}
+		attr.SetType(int(attrType))
+		if name, err = d.readString(); err != nil {
+			return insts, err
+		}
+		attr.SetName(name)
+		weight, err := d.readFloat()
+		if err != nil {
+			return insts, err
+		}
+		attr.SetWeight(weight)
+		direction, err := d.readUvarint()
+		if err != nil {
+			return insts, err
+		}
+		attr.SetDirection(int(direction))
+		switch attr.Type() {
+		case NUMERIC:
+			bounds, err := d.ReadByte()
+			if err != nil {
+				return insts, err
+			}
+			attr.SetHasFixedBounds(bounds == 1)
+			min, err := d.readFloat()
+			if err != nil {
+				return insts, err
+			}
+			max, err := d.readFloat()
+			if err != nil {
+				return insts, err
+			}
+			attr.SetMin(min)
+			attr.SetMax(max)
+		case NOMINAL, STRING:
+			attr.SetHasFixedBounds(attr.IsNominal())
+			numValues, err := d.readLength()
+			if err != nil {
+				return insts, err
+			}
+			for j := 0; j < numValues; j++ {
+				value, err := d.readString()
+				if err != nil {
+					return insts, err
+				}
+				if _, present := attr.ValuesIndexes()[value]; present {
+					return insts, fmt.Errorf("binary: duplicate value '%s' for '%s'", value, attr.Name())
+				}
+				attr.AddStringValue(value)
+			}
+		case DATE:
+			format, err := d.readString()
+			if err != nil {
+				return insts, err
+			}
+			if err := attr.SetDateFormat(format); err != nil {
+				return insts, err
+			}
+		case RELATIONAL:
+			relation, err := d.readHeader()
+			if err != nil {
+				return insts, err
+			}
+			attr.SetRelation(&relation)
+			numBags, err := d.readLength()
+			if err != nil {
+				return insts, err
+			}
+			for j := 0; j < numBags; j++ {
+				bag := NewInstancesWithInst(relation, 0)
+				if err := d.readRows(&bag); err != nil {
+					return insts, err
+				}
+				attr.AddRelation(bag)
+			}
+		default:
+			return insts, fmt.Errorf("binary: unsupported type %d for '%s'", attr.Type(), attr.Name())
+		}
+		attrs[i] = attr
+	}
+	insts.SetAttributes(attrs)
+	return insts, nil
+}
+
+func (d *binaryDecoder) readRows(insts *Instances) error {
+	attrs := insts.Attributes()
+	numRows, err := d.readLength()
+	if err != nil {
+		return err
+	}
+	instances := make([]Instance, 0, boundedCapacity(numRows))
+	for r := 0; r < numRows; r++ {
+		weight, err := d.readFloat()
+		if err != nil {
+			return err
+		}
+		format, err := d.readUvarint()
+		if err != nil {
+			return err
+		}
+		numValues, err := d.readLength()
+		if err != nil {
+			return err
+		}
+		if numValues > len(attrs) || (format == binaryDense && numValues != len(attrs)) {
+			return fmt.Errorf("binary: row %d has %d values, there are %d attributes", r+1, numValues, len(attrs))
+		}
+		var indices []int
+		if format == binarySparse {
+			indices = make([]int, numValues)
+			last := -1
+			for i := range indices {
+				delta, err := d.readLength()
+				if err != nil {
+					return err
+				}
+				if delta == 0 || last+delta >= len(attrs) {
+					return fmt.Errorf("binary: bad attribute index in row %d", r+1)
+				}
+				last += delta
+				indices[i] = last
+			}
+		} else if format != binaryDense {
+			return fmt.Errorf("binary: bad row format %d", format)
+		}
+		values := make([]float64, numValues)
+		for i := range values {
+			value, err := d.readFloat()
+			if err != nil {
+				return err
+			}
+			idx := i
+			if indices != nil {
+				idx = indices[i]
+			}
+			if err := checkBinaryValue(&attrs[idx], value); err != nil {
+				return fmt.Errorf("binary: row %d: %s", r+1, err.Error())
+			}
+			values[i] = value
+		}
+		if indices != nil {
+			instances = append(instances, NewSparseInstance(weight, values, indices, len(attrs)))
+		} else {
+			instances = append(instances, NewDenseInstance(weight, values))
+		}
+	}
+	insts.SetInstances(instances)
+	return nil
+}
+
+//Reads the rows written by writeCSR into a matrix, the arrays are read in
+//chunks as their sizes come from the input
+func (d *binaryDecoder) readCSR(insts *Instances) error {
+	numRows, err := d.readLength()
+	if err != nil {
+		return err
+	}
+	nnz, err := d.readLength()
+	if err != nil {
+		return err
+	}
+	padding, err := d.read(binaryPadding(d.n))
+	if err != nil {
+		return err
+	}
+	for _, b := range padding {
+		if b != 0 {
+			return fmt.Errorf("binary: bad padding before the rows")
+		}
+	}
+	m := new(CSRMatrix)
+	m.numAttributes = len(insts.attributes)
+	m.weights = make([]float64, 0, boundedCapacity(numRows))
+	for r := 0; r < numRows; r++ {
+		weight, err := d.readFloat()
+		if err != nil {
+			return err
+		}
+		m.weights = append(m.weights, weight)
+	}
+	m.rowPtr = make([]int64, 0, boundedCapacity(numRows+1))
+	for r := 0; r <= numRows; r++ {
+		offset, err := d.readUint64()
+		if err != nil {
+			return err
+		}
+		m.rowPtr = append(m.rowPtr, int64(offset))
+	}
+	m.values = make([]float64, 0, boundedCapacity(nnz))
+	for p := 0; p < nnz; p++ {
+		value, err := d.readFloat()
+		if err != nil {
+			return err
+		}
+		m.values = append(m.values, value)
+	}
+	m.indices = make([]int32, 0, boundedCapacity(nnz))
+	for p := 0; p < nnz; p++ {
+		idx, err := d.readUint32()
+		if err != nil {
+			return err
+		}
+		m.indices = append(m.indices, int32(idx))
+	}
+	if err := m.check(insts.attributes); err != nil {
+		return fmt.Errorf("binary: %s", err.Error())
+	}
+	insts.setCSR(m)
+	return nil
+}
+
+//Bounds the preallocation for a count that comes from the input
+func boundedCapacity(count int) int {
+	if count > 1<<20 {
+		return 1 << 20
+	}
+	return count
+}
+
+//Checks that an index stored as value is in the range of the attribute
+func checkBinaryValue(attr *Attribute, value float64) error {
+	if math.IsNaN(value) {
+		return nil
+	}
+	size := 0
+	switch attr.Type() {
+	case NOMINAL, STRING:
+		size = len(attr.Values())
+	case RELATIONAL:
+		if attr.relationalValues != nil {
+			size = len(*attr.relationalValues)
+		}
+	default:
+		return nil
+	}
+	if value < 0 || int(value) >= size {
+		return fmt.Errorf("value %v out of range for '%s'", value, attr.Name())
+	}
+	return nil
+}
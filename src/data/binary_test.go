@@ -0,0 +1,137 @@
+package data
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	for _, text := range []string{weatherARFF, reviewsARFF} {
+		insts := readTestARFF(t, text)
+		var buf bytes.Buffer
+		if err := WriteBinary(&buf, insts); err != nil {
+			t.Fatal(err)
+		}
+		read, err := ReadBinary(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if read.ClassIndex() != insts.ClassIndex() {
+			t.Errorf("class index %d, want %d", read.ClassIndex(), insts.ClassIndex())
+		}
+		if got, want := arffString(t, read), arffString(t, insts); got != want {
+			t.Errorf("read dataset differs:\n%s\nwant:\n%s", got, want)
+		}
+	}
+}
+
+func TestBinarySparseFile(t *testing.T) {
+	insts := readTestARFF(t, weatherARFF)
+	options := NewArffOptions()
+	options.Sparse = true
+	var buf bytes.Buffer
+	if err := WriteARFF(&buf, insts, options); err != nil {
+		t.Fatal(err)
+	}
+	sparse := readTestARFF(t, buf.String())
+	path := filepath.Join(t.TempDir(), "weather.bin")
+	if err := SaveBinary(path, sparse); err != nil {
+		t.Fatal(err)
+	}
+	read, err := LoadBinary(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !read.Instance(0).IsSparse() {
+		t.Errorf("sparse instances read as dense ones")
+	}
+	if got, want := arffString(t, read), arffString(t, insts); got != want {
+		t.Errorf("read dataset differs:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestBinaryCorrupt(t *testing.T) {
+	if _, err := ReadBinary(strings.NewReader("@relation weather")); err != ErrNotBinary {
+		t.Errorf("ARFF read as binary: %v", err)
+	}
+	insts := readTestARFF(t, weatherARFF)
+	var buf bytes.Buffer
+	if err := WriteBinary(&buf, insts); err != nil {
+		t.Fatal(err)
+	}
+	content := buf.Bytes()
+	content[len(content)-6] ^= 0xff
+	if _, err := ReadBinary(bytes.NewReader(content)); err == nil {
+		t.Errorf("corrupt data was read")
+	}
+	if _, err := ReadBinary(bytes.NewReader(content[:len(content)/2])); err == nil {
+		t.Errorf("truncated data was read")
+	}
+}
+
+func TestBinaryVersion(t *testing.T) {
+	insts := readTestARFF(t, weatherARFF)
+	var buf bytes.Buffer
+	if err := WriteBinary(&buf, insts); err != nil {
+		t.Fatal(err)
+	}
+	content := buf.Bytes()
+	if string(content[:4]) != BINARY_MAGIC || content[4] != BINARY_VERSION {
+		t.Fatalf("file starts with %q", content[:5])
+	}
+	content[4] = 9
+	if _, err := ReadBinary(bytes.NewReader(content)); err == nil || !strings.Contains(err.Error(), "version 9") {
+		t.Errorf("version 9 gave error %v", err)
+	}
+	//only files written by SaveBinaryCSR can be mapped
+	path := filepath.Join(t.TempDir(), "weather.bin")
+	if err := SaveBinary(path, insts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MapBinary(path); err == nil || !strings.Contains(err.Error(), "SaveBinaryCSR") {
+		t.Errorf("mapping a version %d file gave error %v", BINARY_VERSION, err)
+	}
+}
+
+//Stored zeros, missing sparse values, weights and the attributes' weights and
+//directions are kept
+func TestBinaryKeepsDetails(t *testing.T) {
+	insts := readTestARFF(t, reviewsARFF)
+	if err := insts.SetAttributeWeight(1, 0.25); err != nil {
+		t.Fatal(err)
+	}
+	sparse := NewSparseInstance(3.0, []float64{0, MissingValue(), 1}, []int{1, 2, 3}, 4)
+	insts.SetInstances(append(insts.Instances(), sparse))
+	var buf bytes.Buffer
+	if err := WriteBinary(&buf, insts); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadBinary(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := read.Instance(4)
+	if !last.IsSparse() || last.NumValues() != 3 || last.Index(0) != 1 || last.ValueSparse(0) != 0 || !last.IsMissingSparse(1) || last.Weight() != 3 {
+		t.Errorf("sparse instance read as %v with weight %v", last, last.Weight())
+	}
+	if weight := read.Instance(1).Weight(); weight != 2 {
+		t.Errorf("instance weight %v, want 2", weight)
+	}
+	for j := range insts.Attributes() {
+		want, got := insts.Attribute(j), read.Attribute(j)
+		if got.Weight() != want.Weight() || got.Direction() != want.Direction() || got.DateFormat() != want.DateFormat() {
+			t.Errorf("attribute %d read with weight %v, direction %d and format %q, want %v, %d and %q", j,
+				got.Weight(), got.Direction(), got.DateFormat(), want.Weight(), want.Direction(), want.DateFormat())
+		}
+	}
+	empty := NewInstancesWithInst(insts, 0)
+	buf.Reset()
+	if err := WriteBinary(&buf, empty); err != nil {
+		t.Fatal(err)
+	}
+	if read, err := ReadBinary(&buf); err != nil || len(read.Instances()) != 0 || len(read.Attributes()) != 4 {
+		t.Errorf("empty dataset read with %d attributes and error %v", len(read.Attributes()), err)
+	}
+}
@@ -0,0 +1,367 @@
+package data
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+//Rows of a dataset in compressed sparse row format: the non-zero values of all
+//the rows are stored one row after another in values, with their attribute
+//indexes in indices, and row r takes positions rowPtr[r] to rowPtr[r+1]. Only
+//three arrays (and the weights) are allocated whatever the number of rows,
+//and they can be memory-mapped from a binary file, see MapBinary.
+//
+//The columns are built on demand for attribute evaluators, see Column
+type CSRMatrix struct {
+	numAttributes int
+	weights       []float64
+	rowPtr        []int64
+	indices       []int32
+	values        []float64
+	//Transpose of the matrix, nil until Column is called and after a value
+	//is changed
+	colPtr    []int64
+	colRows   []int32
+	colValues []float64
+	//Releases the memory mapping, nil if the arrays are not mapped
+	unmap func() error
+	//Whether the values of a row were copied out of the matrix, which then
+	//no longer holds those of the instances
+	detached bool
+}
+
+//Creates a matrix with the non-zero values of the instances, which must all
+//have numAttributes attributes
+func NewCSRMatrix(instances []Instance, numAttributes int) (*CSRMatrix, error) {
+	m := new(CSRMatrix)
+	m.numAttributes = numAttributes
+	m.weights = make([]float64, len(instances))
+	m.rowPtr = make([]int64, len(instances)+1)
+	nnz := 0
+	for r, instance := range instances {
+		if instance.NumAttributes() != numAttributes {
+			return nil, fmt.Errorf("CSR: instance %d has %d attributes, expected %d", r+1, instance.NumAttributes(), numAttributes)
+		}
+		for j := 0; j < instance.NumValues(); j++ {
+			if instance.ValueSparse(j) != 0 {
+				nnz++
+			}
+		}
+	}
+	m.indices = make([]int32, 0, nnz)
+	m.values = make([]float64, 0, nnz)
+	for r, instance := range instances {
+		m.weights[r] = instance.Weight()
+		for j := 0; j < instance.NumValues(); j++ {
+			if value := instance.ValueSparse(j); value != 0 {
+				m.indices = append(m.indices, int32(instance.Index(j)))
+				m.values = append(m.values, value)
+			}
+		}
+		m.rowPtr[r+1] = int64(len(m.values))
+	}
+	return m, nil
+}
+
+//Returns a copy of the dataset whose instances are rows of a CSR matrix, see
+//CSRInstance. Zero values are not stored, so all the instances are sparse
+func NewInstancesCSR(instances Instances) (Instances, error) {
+	csr := NewInstancesWithInst(instances, 0)
+	csr.classIndex = instances.classIndex
+	m, err := NewCSRMatrix(instances.instances, len(instances.attributes))
+	if err != nil {
+		return csr, err
+	}
+	csr.setCSR(m)
+	return csr, nil
+}
+
+//Makes the rows of the matrix the instances of the dataset
+func (i *Instances) setCSR(m *CSRMatrix) {
+	insts := make([]Instance, m.NumRows())
+	for r := range insts {
+		insts[r] = m.Row(r)
+	}
+	i.SetInstances(insts)
+	i.csr = m
+}
+
+//Returns the matrix holding the instances of the dataset, nil if they are not
+//stored in one. Its rows may be in another order than the instances if these
+//were reordered (Randomize, Stratify, Sort), statistics over them are the same
+//unless the matrix is Detached
+func (i *Instances) CSR() *CSRMatrix {
+	return i.csr
+}
+
+//Returns the instance for a row of the matrix, it refers to the matrix's arrays
+func (m *CSRMatrix) Row(r int) *CSRInstance {
+	return &CSRInstance{matrix: m, row: r}
+}
+
+//Returns the value of an attribute in a row
+func (m *CSRMatrix) Value(r, attIndex int) float64 {
+	start, end := m.rowPtr[r], m.rowPtr[r+1]
+	indices := m.indices[start:end]
+	position := sort.Search(len(indices), func(j int) bool { return int(indices[j]) >= attIndex })
+	if position < len(indices) && int(indices[position]) == attIndex {
+		return m.values[start+int64(position)]
+	}
+	return 0.0
+}
+
+//Returns the rows with a non-zero value for the attribute, in ascending
+//order, and their values. The columns of all the attributes are built on the
+//first call, in time and memory proportional to the number of values. The
+//slices must not be modified
+func (m *CSRMatrix) Column(attIndex int) ([]int32, []float64) {
+	if m.colPtr == nil {
+		m.transpose()
+	}
+	start, end := m.colPtr[attIndex], m.colPtr[attIndex+1]
+	return m.colRows[start:end], m.colValues[start:end]
+}
+
+//Builds the columns with a counting sort of the values by attribute
+func (m *CSRMatrix) transpose() {
+	colPtr := make([]int64, m.numAttributes+1)
+	for _, idx := range m.indices {
+		colPtr[idx+1]++
+	}
+	for j := 0; j < m.numAttributes; j++ {
+		colPtr[j+1] += colPtr[j]
+	}
+	next := make([]int64, m.numAttributes)
+	copy(next, colPtr)
+	m.colRows = make([]int32, len(m.indices))
+	m.colValues = make([]float64, len(m.values))
+	for r := 0; r < m.NumRows(); r++ {
+		for p := m.rowPtr[r]; p < m.rowPtr[r+1]; p++ {
+			idx := m.indices[p]
+			m.colRows[next[idx]] = int32(r)
+			m.colValues[next[idx]] = m.values[p]
+			next[idx]++
+		}
+	}
+	m.colPtr = colPtr
+}
+
+//Checks that the arrays describe a valid matrix for the attributes, used on
+//data read from files
+func (m *CSRMatrix) check(attrs []Attribute) error {
+	if len(m.rowPtr) != len(m.weights)+1 || m.rowPtr[0] != 0 || m.rowPtr[len(m.weights)] != int64(len(m.values)) || len(m.indices) != len(m.values) {
+		return fmt.Errorf("CSR: inconsistent array sizes")
+	}
+	//the offsets are all checked before the indexes are read with them
+	for r := 0; r < m.NumRows(); r++ {
+		if m.rowPtr[r] > m.rowPtr[r+1] || m.rowPtr[r+1] > int64(len(m.indices)) {
+			return fmt.Errorf("CSR: bad row offsets for row %d", r+1)
+		}
+	}
+	for r := 0; r < m.NumRows(); r++ {
+		start, end := m.rowPtr[r], m.rowPtr[r+1]
+		last := int32(-1)
+		for p := start; p < end; p++ {
+			idx := m.indices[p]
+			if idx <= last || int(idx) >= len(attrs) {
+				return fmt.Errorf("CSR: bad attribute index in row %d", r+1)
+			}
+			if err := checkBinaryValue(&attrs[idx], m.values[p]); err != nil {
+				return fmt.Errorf("CSR: row %d: %s", r+1, err.Error())
+			}
+			last = idx
+		}
+	}
+	return nil
+}
+
+//Releases the memory mapping of a matrix loaded by MapBinary, the dataset and
+//its instances must not be used afterwards. It does nothing for other matrices
+func (m *CSRMatrix) Close() error {
+	if m.unmap == nil {
+		return nil
+	}
+	unmap := m.unmap
+	m.unmap = nil
+	m.weights, m.rowPtr, m.indices, m.values = nil, nil, nil, nil
+	m.colPtr, m.colRows, m.colValues = nil, nil, nil
+	return unmap()
+}
+
+//Gets methods
+
+func (m *CSRMatrix) NumRows() int {
+	return len(m.weights)
+}
+
+func (m *CSRMatrix) NumAttributes() int {
+	return m.numAttributes
+}
+
+//Returns the number of stored values
+func (m *CSRMatrix) NumNonZero() int {
+	return len(m.values)
+}
+
+func (m *CSRMatrix) Weight(r int) float64 {
+	return m.weights[r]
+}
+
+//Whether the values of a row were copied out of the matrix by
+//CSRInstance.SetValue, the matrix then no longer holds all the values of the
+//instances
+func (m *CSRMatrix) Detached() bool {
+	return m.detached
+}
+
+//Whether the arrays are memory-mapped from a file
+func (m *CSRMatrix) Mapped() bool {
+	return m.unmap != nil
+}
+
+//Instance that is a row of a CSRMatrix, it is always sparse. Its stored
+//values are changed in the matrix, setting one to zero keeps it stored. Setting
+//a non-zero value of an attribute not stored copies the row out of the matrix
+//into a SparseInstance owned by the instance, which holds its values from then
+//on, the matrix keeps the old ones
+type CSRInstance struct {
+	matrix *CSRMatrix
+	row    int
+	header *Header
+	//The row's values once copied out of the matrix, nil while they are in it
+	own *SparseInstance
+}
+
+func (i *CSRInstance) start() int64 {
+	return i.matrix.rowPtr[i.row]
+}
+
+func (i *CSRInstance) Value(attIndex int) float64 {
+	if i.own != nil {
+		return i.own.Value(attIndex)
+	}
+	return i.matrix.Value(i.row, attIndex)
+}
+
+func (i *CSRInstance) ValueSparse(position int) float64 {
+	if i.own != nil {
+		return i.own.ValueSparse(position)
+	}
+	return i.matrix.values[i.start()+int64(position)]
+}
+
+func (i *CSRInstance) Index(position int) int {
+	if i.own != nil {
+		return i.own.Index(position)
+	}
+	return int(i.matrix.indices[i.start()+int64(position)])
+}
+
+func (i *CSRInstance) NumValues() int {
+	if i.own != nil {
+		return i.own.NumValues()
+	}
+	return int(i.matrix.rowPtr[i.row+1] - i.start())
+}
+
+func (i *CSRInstance) NumAttributes() int {
+	return i.matrix.numAttributes
+}
+
+func (i *CSRInstance) IsMissingValue(attIndex int) bool {
+	return math.IsNaN(i.Value(attIndex))
+}
+
+func (i *CSRInstance) IsMissingSparse(position int) bool {
+	return math.IsNaN(i.ValueSparse(position))
+}
+
+func (i *CSRInstance) ClassValue(classIndex int) float64 {
+	if classIndex < 0 {
+		return MissingValue()
+	}
+	return i.Value(classIndex)
+}
+
+func (i *CSRInstance) ClassMissing(classIndex int) bool {
+	if classIndex < 0 {
+		return true
+	}
+	return i.IsMissingValue(classIndex)
+}
+
+func (i *CSRInstance) Weight() float64 {
+	if i.own != nil {
+		return i.own.Weight()
+	}
+	return i.matrix.weights[i.row]
+}
+
+func (i *CSRInstance) SetWeight(weight float64) {
+	if i.own != nil {
+		i.own.SetWeight(weight)
+		return
+	}
+	i.matrix.weights[i.row] = weight
+}
+
+func (i *CSRInstance) SetValue(attIndex int, value float64) {
+	if i.own != nil {
+		i.own.SetValue(attIndex, value)
+		return
+	}
+	start, end := i.start(), i.matrix.rowPtr[i.row+1]
+	for p := start; p < end; p++ {
+		if int(i.matrix.indices[p]) == attIndex {
+			i.SetValueSparse(int(p-start), value)
+			return
+		}
+	}
+	if value != 0 {
+		//there is no room for the value in the matrix
+		i.own = i.Copy().(*SparseInstance)
+		i.own.SetValue(attIndex, value)
+		i.matrix.detached = true
+	}
+}
+
+func (i *CSRInstance) SetValueSparse(position int, value float64) {
+	if i.own != nil {
+		i.own.SetValueSparse(position, value)
+		return
+	}
+	i.matrix.values[i.start()+int64(position)] = value
+	i.matrix.colPtr, i.matrix.colRows, i.matrix.colValues = nil, nil, nil
+}
+
+func (i *CSRInstance) IsSparse() bool {
+	return true
+}
+
+//Returns a SparseInstance with the values of the row
+func (i *CSRInstance) Copy() Instance {
+	if i.own != nil {
+		inst := i.own.Copy()
+		inst.SetHeader(i.header)
+		return inst
+	}
+	start, end := i.start(), i.matrix.rowPtr[i.row+1]
+	values := make([]float64, end-start)
+	copy(values, i.matrix.values[start:end])
+	indices := make([]int, end-start)
+	for p := range indices {
+		indices[p] = int(i.matrix.indices[start+int64(p)])
+	}
+	inst := NewSparseInstance(i.Weight(), values, indices, i.matrix.numAttributes)
+	inst.header = i.header
+	return inst
+}
+
+func (i *CSRInstance) Header() *Header {
+	return i.header
+}
+
+func (i *CSRInstance) SetHeader(header *Header) {
+	i.header = header
+}
@@ -0,0 +1,108 @@
+package data
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBinaryCSRRoundTrip(t *testing.T) {
+	insts := readTestARFF(t, weatherARFF)
+	var buf bytes.Buffer
+	if err := WriteBinaryCSR(&buf, insts); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadBinary(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.CSR() == nil {
+		t.Fatal("ReadBinary did not return a CSR dataset")
+	}
+	if got, want := arffString(t, read), arffString(t, insts); got != want {
+		t.Errorf("read dataset differs:\n%s\nwant:\n%s", got, want)
+	}
+	path := filepath.Join(t.TempDir(), "weather.bin")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mapped, err := MapBinary(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mapped.CSR().Close()
+	if got, want := arffString(t, mapped), arffString(t, insts); got != want {
+		t.Errorf("mapped dataset differs:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestBinaryCSRColumn(t *testing.T) {
+	insts := readTestARFF(t, weatherARFF)
+	csr, err := NewInstancesCSR(insts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, values := csr.CSR().Column(1)
+	//temperature is 0 in row 12, which is not stored
+	if len(rows) != 13 || rows[11] != 12 || values[11] != 81 {
+		t.Errorf("Column(1) = %v %v", rows, values)
+	}
+}
+
+//Writes a CSR file whose middle row offset is out of range, with a valid
+//checksum, it must be rejected without a panic
+func TestBinaryCSRBadOffsets(t *testing.T) {
+	insts := readTestARFF(t, "@relation r\n@attribute a numeric\n@attribute b numeric\n@data\n1,0\n0,2\n")
+	var buf bytes.Buffer
+	if err := WriteBinaryCSR(&buf, insts); err != nil {
+		t.Fatal(err)
+	}
+	content := buf.Bytes()
+	//the file ends with the row offsets (3), values (2), indexes (2) and the
+	//checksum
+	rowPtr := len(content) - 4 - 2*4 - 2*8 - 3*8
+	binary.LittleEndian.PutUint64(content[rowPtr+8:], 100)
+	binary.LittleEndian.PutUint32(content[len(content)-4:], crc32.ChecksumIEEE(content[:len(content)-4]))
+	if _, err := ReadBinary(bytes.NewReader(content)); err == nil || !strings.HasPrefix(err.Error(), "binary:") {
+		t.Errorf("ReadBinary error = %v, want a binary: error", err)
+	}
+	path := filepath.Join(t.TempDir(), "bad.bin")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MapBinary(path); err == nil || !strings.HasPrefix(err.Error(), "binary:") {
+		t.Errorf("MapBinary error = %v, want a binary: error", err)
+	}
+}
+
+func TestCSRInstanceSetValue(t *testing.T) {
+	insts := readTestARFF(t, weatherARFF)
+	csr, err := NewInstancesCSR(insts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//temperature is 0 in the 12th row, so it is not stored
+	row := csr.Instance(11)
+	row.SetValue(1, 70)
+	if row.Value(1) != 70 || row.Value(2) != 90 {
+		t.Errorf("values after SetValue: %v %v", row.Value(1), row.Value(2))
+	}
+	if !csr.CSR().Detached() || csr.CSR().Value(11, 1) != 0 {
+		t.Errorf("the row was not copied out of the matrix")
+	}
+	var buf bytes.Buffer
+	if err := WriteBinaryCSR(&buf, csr); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadBinary(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := read.Instance(11).Value(1); got != 70 {
+		t.Errorf("written value = %v, want 70", got)
+	}
+}
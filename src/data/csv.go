@@ -0,0 +1,307 @@
+package data
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+//Options of the CSV loader and saver
+type CSVOptions struct {
+	//Field separator
+	Delimiter rune
+	//Whether the first row holds the attribute names, otherwise the
+	//attributes are named att1, att2, ...
+	Header bool
+	//Values read as missing, the first one is written for missing values
+	MissingValues []string
+	//Number of rows used to infer the attribute types, -1 for all of them.
+	//The types are inferred again from all the rows if a later value does
+	//not fit
+	SampleSize int
+	//Columns with more distinct values than this are read as STRING instead
+	//of NOMINAL
+	MaxNominalValues int
+	//Format of DATE attributes, a java SimpleDateFormat pattern
+	DateFormat string
+	//Types given by column name (NUMERIC, NOMINAL, STRING or DATE), they
+	//override the inferred ones
+	Types map[string]int
+}
+
+//Returns the default options: comma separated values with a header row, ? or
+//empty fields as missing values and types inferred from the first 100 rows
+func NewCSVOptions() CSVOptions {
+	var options CSVOptions
+	options.Delimiter = ','
+	options.Header = true
+	options.MissingValues = []string{ARFF_MISSING, ""}
+	options.SampleSize = 100
+	options.MaxNominalValues = 20
+	options.DateFormat = DEFAULT_DATE_FORMAT
+	options.Types = make(map[string]int)
+	return options
+}
+
+//Reads a dataset in CSV format, like weka's CSVLoader. A column is NUMERIC if
+//all its values are numbers, DATE if all of them are dates in
+//options.DateFormat, NOMINAL if it has at most options.MaxNominalValues
+//distinct values and STRING otherwise, see CSVOptions.SampleSize. The nominal
+//values are taken from all the rows in order of appearance. No class
+//attribute is set, use SetClassIndex afterwards
+func ReadCSV(reader io.Reader, options CSVOptions) (Instances, error) {
+	insts := NewInstancesWithClassIndex(-1)
+	csvReader := csv.NewReader(reader)
+	csvReader.Comma = options.Delimiter
+	csvReader.TrimLeadingSpace = true
+	rows, err := csvReader.ReadAll()
+	if err != nil {
+		return insts, err
+	}
+	if len(rows) == 0 {
+		return insts, fmt.Errorf("CSV: no data found")
+	}
+	names := make([]string, len(rows[0]))
+	for i := range names {
+		names[i] = "att" + strconv.Itoa(i+1)
+	}
+	first := 0
+	if options.Header {
+		for i, name := range rows[0] {
+			names[i] = strings.TrimSpace(name)
+		}
+		first = 1
+	}
+	rows = rows[first:]
+	for name := range options.Types {
+		if indexOf(names, name) < 0 {
+			return insts, fmt.Errorf("CSV: type given for unknown column '%s'", name)
+		}
+	}
+	missing := make(map[string]bool, len(options.MissingValues))
+	for _, token := range options.MissingValues {
+		missing[token] = true
+	}
+	attrs := make([]Attribute, len(names))
+	for col, name := range names {
+		attr := NewAttribute()
+		attr.SetName(name)
+		attr.SetIndex(col)
+		attr.SetDirection(INPUT)
+		attr_type, present := options.Types[name]
+		if !present {
+			attr_type = inferCSVType(rows, col, missing, options)
+		}
+		attr.SetType(attr_type)
+		switch attr_type {
+		case NUMERIC, STRING:
+		case DATE:
+			if err := attr.SetDateFormat(options.DateFormat); err != nil {
+				return insts, err
+			}
+		case NOMINAL:
+			for _, row := range rows {
+				if val := strings.TrimSpace(row[col]); !missing[val] {
+					attr.AddStringValue(val)
+				}
+			}
+			attr.SetHasFixedBounds(true)
+		default:
+			return insts, fmt.Errorf("CSV: unsupported type %d for column '%s'", attr_type, name)
+		}
+		attrs[col] = attr
+	}
+	insts.SetAttributes(attrs)
+	instances := make([]Instance, 0, len(rows))
+	for r, row := range rows {
+		values := make([]float64, len(attrs))
+		for col, field := range row {
+			val := strings.TrimSpace(field)
+			if missing[val] {
+				values[col] = MissingValue()
+				continue
+			}
+			value, err := insts.parseValue(&attrs[col], val, true)
+			if err != nil {
+				return insts, fmt.Errorf("CSV: row %d: %s", r+first+1, err.Error())
+			}
+			values[col] = value
+		}
+		instance := NewDenseInstance(1.0, values)
+		instances = append(instances, instance)
+	}
+	insts.SetInstances(instances)
+	return insts, nil
+}
+
+//Reads a dataset from a CSV file, see ReadCSV
+func LoadCSV(path string, options CSVOptions) (Instances, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return NewInstancesWithClassIndex(-1), err
+	}
+	defer file.Close()
+	insts, err := ReadCSV(file, options)
+	//the relation is named after the file, like in weka
+	insts.SetDatasetName(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	return insts, err
+}
+
+//Infers the type of a column from the first options.SampleSize rows. The type
+//is checked on the rest of the rows and inferred again from all of them if
+//some value does not fit it, e.g. a word in a column of numbers or too many
+//distinct values for a NOMINAL column
+func inferCSVType(rows [][]string, col int, missing map[string]bool, options CSVOptions) int {
+	sample := rows
+	if options.SampleSize >= 0 && options.SampleSize < len(rows) {
+		sample = rows[:options.SampleSize]
+	}
+	attrType := inferCSVTypeRows(sample, col, missing, options)
+	if len(sample) < len(rows) && !csvTypeFits(attrType, rows, col, missing, options) {
+		attrType = inferCSVTypeRows(rows, col, missing, options)
+	}
+	return attrType
+}
+
+//Infers the type of a column from the given rows
+func inferCSVTypeRows(rows [][]string, col int, missing map[string]bool, options CSVOptions) int {
+	numeric, date := true, true
+	dateAttr := NewAttribute()
+	dateAttr.SetType(DATE)
+	if dateAttr.SetDateFormat(options.DateFormat) != nil {
+		date = false
+	}
+	distinct := make(map[string]bool)
+	for _, row := range rows {
+		val := strings.TrimSpace(row[col])
+		if missing[val] {
+			continue
+		}
+		distinct[val] = true
+		if numeric {
+			number, err := strconv.ParseFloat(val, 64)
+			numeric = err == nil && !math.IsNaN(number)
+		}
+		if date {
+			_, err := dateAttr.ParseDate(val)
+			date = err == nil
+		}
+	}
+	switch {
+	case len(distinct) == 0:
+		//all missing, like weka
+		return NUMERIC
+	case numeric:
+		return NUMERIC
+	case date:
+		return DATE
+	case len(distinct) <= options.MaxNominalValues:
+		return NOMINAL
+	}
+	return STRING
+}
+
+//Whether all the values of a column can be read with the type
+func csvTypeFits(attrType int, rows [][]string, col int, missing map[string]bool, options CSVOptions) bool {
+	dateAttr := NewAttribute()
+	dateAttr.SetType(DATE)
+	if attrType == DATE && dateAttr.SetDateFormat(options.DateFormat) != nil {
+		return false
+	}
+	distinct := make(map[string]bool)
+	for _, row := range rows {
+		val := strings.TrimSpace(row[col])
+		if missing[val] {
+			continue
+		}
+		switch attrType {
+		case NUMERIC:
+			if number, err := strconv.ParseFloat(val, 64); err != nil || math.IsNaN(number) {
+				return false
+			}
+		case DATE:
+			if _, err := dateAttr.ParseDate(val); err != nil {
+				return false
+			}
+		case NOMINAL:
+			distinct[val] = true
+			if len(distinct) > options.MaxNominalValues {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+//Writes the dataset in CSV format, like weka's CSVSaver. The first row holds
+//the attribute names if options.Header is set, missing values are written
+//as the first of options.MissingValues and instance weights are not written
+func WriteCSV(writer io.Writer, instances Instances, options CSVOptions) error {
+	csvWriter := csv.NewWriter(writer)
+	csvWriter.Comma = options.Delimiter
+	missing := ARFF_MISSING
+	if len(options.MissingValues) > 0 {
+		missing = options.MissingValues[0]
+	}
+	attrs := instances.Attributes()
+	if options.Header {
+		names := make([]string, len(attrs))
+		for i := range attrs {
+			names[i] = attrs[i].Name()
+		}
+		if err := csvWriter.Write(names); err != nil {
+			return err
+		}
+	}
+	for _, instance := range instances.Instances() {
+		record := make([]string, len(attrs))
+		for idx := range attrs {
+			value := instance.Value(idx)
+			attr := &attrs[idx]
+			switch {
+			case math.IsNaN(value):
+				record[idx] = missing
+			case attr.IsNominal() || attr.IsString():
+				record[idx] = attr.Values()[int(value)]
+			case attr.IsDate():
+				record[idx] = attr.FormatDate(value)
+			case attr.Type() == NUMERIC:
+				record[idx] = strconv.FormatFloat(value, 'g', -1, 64)
+			default:
+				return fmt.Errorf("CSV: cannot write attribute '%s' of type %d", attr.Name(), attr.Type())
+			}
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+//Writes the dataset to a CSV file, see WriteCSV
+func SaveCSV(path string, instances Instances, options CSVOptions) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteCSV(file, instances, options); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
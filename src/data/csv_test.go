@@ -0,0 +1,62 @@
+package data
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	insts := readTestARFF(t, weatherARFF)
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, insts, NewCSVOptions()); err != nil {
+		t.Fatal(err)
+	}
+	want := buf.String()
+	read, err := ReadCSV(&buf, NewCSVOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	//the nominal values are read in order of appearance, so the CSV is
+	//compared
+	var again bytes.Buffer
+	if err := WriteCSV(&again, read, NewCSVOptions()); err != nil {
+		t.Fatal(err)
+	}
+	if got := again.String(); got != want {
+		t.Errorf("read dataset differs:\n%s\nwant:\n%s", got, want)
+	}
+	if attr := read.Attribute(1); attr.Type() != NUMERIC {
+		t.Errorf("temperature has type %d, want NUMERIC", attr.Type())
+	}
+}
+
+//Values after the sample that do not fit the inferred type
+func TestCSVTypeAfterSample(t *testing.T) {
+	var text strings.Builder
+	text.WriteString("x,color\n")
+	for r := 0; r < 30; r++ {
+		fmt.Fprintf(&text, "%d,c%d\n", r, r%3)
+	}
+	text.WriteString("n/a,red\n")
+	for r := 0; r < 30; r++ {
+		fmt.Fprintf(&text, "%d,d%d\n", r, r)
+	}
+	options := NewCSVOptions()
+	options.SampleSize = 10
+	insts, err := ReadCSV(strings.NewReader(text.String()), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, color := insts.Attribute(0), insts.Attribute(1)
+	if x.Type() != NOMINAL && x.Type() != STRING {
+		t.Errorf("x has type %d, want NOMINAL or STRING", x.Type())
+	}
+	if color.Type() != STRING {
+		t.Errorf("color has type %d, want STRING", color.Type())
+	}
+	if len(insts.Instances()) != 61 {
+		t.Errorf("read %d instances, want 61", len(insts.Instances()))
+	}
+}
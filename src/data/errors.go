@@ -0,0 +1,34 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+)
+
+//Returned when an operation needs a class attribute and none is set
+var ErrClassNotSet = errors.New("Class index is not set")
+
+//Error found while reading a dataset, Line is the 1-based line number in the
+//input or 0 if the error is not tied to a line
+type ParseError struct {
+	Line int
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+	}
+	return e.Msg
+}
+
+//Creates a ParseError at the given line
+func newParseError(line int, format string, args ...interface{}) *ParseError {
+	return &ParseError{Line: line, Msg: fmt.Sprintf(format, args...)}
+}
+
+//Returned when the input of ReadBinary is not in the binary format
+var ErrNotBinary = errors.New("not a binary dataset")
+
+//Returned when the checksum of a binary dataset does not match its content
+var ErrBadChecksum = errors.New("binary dataset checksum mismatch, the file is corrupt")
@@ -3,76 +3,265 @@ package data
 import (
 	"bufio"
 	"fmt"
-	"math/rand"
+	"io"
+	"math"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
-	"time"
-	//	"syscall"
 )
 
-func ExportToArffFileSparse(data Instances, fileName string, filePath string) {
-	//Select the necessary information
-	name := data.DatasetName()
-	attributes := data.Attributes()
-	instances := data.Instances()
-	//If fileName is undefined so give a particular name to the file
+//Options of the ARFF writer
+type ArffOptions struct {
+	//Writes the instances in sparse format, {index value, ...}, omitting zeros
+	Sparse bool
+	//Writes the weight of instances whose weight is not 1 as a trailing {w}
+	Weights bool
+	//Maximum number of decimal places of numeric values, -1 for the shortest
+	//representation that reads back exactly
+	MaxDecimalPlaces int
+}
+
+//Returns the default options: dense format, weights written, full precision
+func NewArffOptions() ArffOptions {
+	var options ArffOptions
+	options.Sparse = false
+	options.Weights = true
+	options.MaxDecimalPlaces = -1
+	return options
+}
+
+//Writes a dataset in ARFF format one instance at a time, like weka's
+//ArffSaver in incremental mode. Usage:
+//
+//	saver := data.NewArffSaver(writer, header, data.NewArffOptions())
+//	for ... {
+//		if err := saver.WriteInstance(instance); err != nil {...}
+//	}
+//	err := saver.Flush()
+type ArffSaver struct {
+	writer *bufio.Writer
+	//The header of the dataset, its instances are not written
+	header Instances
+	options ArffOptions
+	headerWritten bool
+}
+
+func NewArffSaver(writer io.Writer, header Instances, options ArffOptions) ArffSaver {
+	var saver ArffSaver
+	saver.writer = bufio.NewWriter(writer)
+	saver.header = header
+	saver.options = options
+	saver.headerWritten = false
+	return saver
+}
+
+//Writes the dataset to writer in ARFF format
+func WriteARFF(writer io.Writer, instances Instances, options ArffOptions) error {
+	saver := NewArffSaver(writer, instances, options)
+	if err := saver.WriteHeader(); err != nil {
+		return err
+	}
+	for i := range instances.instances {
+		if err := saver.WriteInstance(instances.instances[i]); err != nil {
+			return err
+		}
+	}
+	return saver.Flush()
+}
+
+//Writes the dataset to the file in ARFF format, the file is overwritten
+func SaveARFF(path string, instances Instances, options ArffOptions) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteARFF(file, instances, options); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+//Writes the instances in sparse ARFF format to filePath/fileName.arff, the
+//dataset name is used if fileName is empty
+func ExportToArffFileSparse(data Instances, fileName string, filePath string) error {
 	if fileName == "" {
-		fileName = strings.Replace(strings.Replace(name+"_"+strconv.FormatInt(rand.Int63(), 10)+"_"+time.Now().String(), "-", "_", -1), ":", "_", -1)
+		fileName = data.DatasetName()
 	}
-	check := func(err error) {
-		if err != nil {
-			panic(fmt.Errorf("The file with name: %s, in path: %s cannot be created! \n Error throw: %s", fileName, filePath, err.Error()))
+	options := NewArffOptions()
+	options.Sparse = true
+	return SaveARFF(filepath.Join(filePath, fileName+".arff"), data, options)
+}
+
+//Writes the @relation and @attribute declarations and the @data line, it is
+//called by the first WriteInstance if it was not called before
+func (s *ArffSaver) WriteHeader() error {
+	if s.headerWritten {
+		return nil
+	}
+	s.headerWritten = true
+	s.writer.WriteString(ARFF_RELATION + " " + Quote(s.header.DatasetName()) + "\n\n")
+	for _, attr := range s.header.Attributes() {
+		if err := s.writeAttribute(attr); err != nil {
+			return err
 		}
 	}
-	var file *os.File
-	var err error
-	if filePath == "" {
-		file, err = os.Create(fileName + ".arff")
-		check(err)
-	} else {
-		file, err = os.Create(filePath + fileName + ".arff")
-		check(err)
-	}
-
-	writer := bufio.NewWriter(file)
-	defer file.Close()
-	fmt.Println(file.Name())
-	writer.WriteString("@relation " + name)
-	writer.WriteString("\n\n")
-	attrsDefinition := ""
-	for _, attr := range attributes {
-		attrsDefinition += "@attribute "
-		attrsDefinition += attr.Name() + " "
-		if attr.Type() == NUMERIC {
-			attrsDefinition += "numeric "
-			if attr.HasFixedBounds() {
-				attrsDefinition += fmt.Sprint("[", attr.Min(), ",", attr.Max())
+	_, err := s.writer.WriteString("\n" + ARFF_DATA + "\n")
+	return err
+}
+
+//Writes the @attribute declaration of the attribute, the declarations of a
+//relational attribute's inner attributes go before its @end
+func (s *ArffSaver) writeAttribute(attr Attribute) error {
+	s.writer.WriteString(ARFF_ATTRIBUTE + " " + Quote(attr.Name()) + " ")
+	switch attr.Type() {
+	case NUMERIC:
+		s.writer.WriteString("numeric")
+		if attr.HasFixedBounds() {
+			s.writer.WriteString(" [" + s.formatNumber(attr.Min()) + "," + s.formatNumber(attr.Max()) + "]")
+		}
+	case NOMINAL:
+		values := make([]string, len(attr.Values()))
+		for i, value := range attr.Values() {
+			values[i] = Quote(value)
+		}
+		s.writer.WriteString("{" + strings.Join(values, ",") + "}")
+	case STRING:
+		s.writer.WriteString("string")
+	case DATE:
+		s.writer.WriteString("date " + quoteDateFormat(attr.DateFormat()))
+	case RELATIONAL:
+		s.writer.WriteString("relational\n")
+		for _, inner := range attr.Relation().Attributes() {
+			if err := s.writeAttribute(inner); err != nil {
+				return err
 			}
-		} else if attr.Type() == NOMINAL {
-			tmp := strings.Replace(fmt.Sprint(attr.Values()), "[", "{", 1)
-			tmp = strings.Replace(tmp, " ", ",", -1)
-			attrsDefinition += fmt.Sprint(strings.Replace(tmp, "]", "}", 1))
 		}
-		attrsDefinition += "\n"
-	}
-	instDeclaration := ""
-	for _, inst := range instances {
-		tmp := "{"
-		for i, val := range inst.RealValues() {
-			idx := inst.Indices()[i]
-			if attributes[idx].Type() == NOMINAL {
-				tmp += fmt.Sprint(idx, " ", attributes[idx].Values()[int(val)], ",")
-			} else if attributes[idx].Type() == NUMERIC {
-				tmp += fmt.Sprint(idx, val, ",")
+		s.writer.WriteString(ARFF_END + " " + Quote(attr.Name()))
+	default:
+		return fmt.Errorf("cannot write attribute '%s' of type %d in ARFF", attr.Name(), attr.Type())
+	}
+	_, err := s.writer.WriteString("\n")
+	return err
+}
+
+//Writes one data row, dense or sparse depending on the options
+func (s *ArffSaver) WriteInstance(instance Instance) error {
+	if err := s.WriteHeader(); err != nil {
+		return err
+	}
+	line, err := s.instanceString(s.header, instance)
+	if err != nil {
+		return err
+	}
+	if s.options.Weights && instance.Weight() != 1 {
+		line += ",{" + s.formatNumber(instance.Weight()) + "}"
+	}
+	_, err = s.writer.WriteString(line + "\n")
+	return err
+}
+
+//Writes the buffered output to the underlying writer
+func (s *ArffSaver) Flush() error {
+	if err := s.WriteHeader(); err != nil {
+		return err
+	}
+	return s.writer.Flush()
+}
+
+//Returns the instance as an ARFF data row without weight
+func (s *ArffSaver) instanceString(header Instances, instance Instance) (string, error) {
+	attrs := header.Attributes()
+	if !s.options.Sparse {
+		vals := make([]string, len(attrs))
+		for idx := range attrs {
+			val, err := s.valueString(&attrs[idx], instance.Value(idx))
+			if err != nil {
+				return "", err
+			}
+			vals[idx] = val
+		}
+		return strings.Join(vals, ","), nil
+	}
+	vals := make([]string, 0)
+	for j := 0; j < instance.NumValues(); j++ {
+		idx, value := instance.Index(j), instance.ValueSparse(j)
+		if idx >= len(attrs) {
+			return "", fmt.Errorf("instance has a value for attribute %d, there are %d attributes", idx, len(attrs))
+		}
+		//a string or relational value with index 0 is not a default value
+		if value == 0 && !attrs[idx].IsString() && !attrs[idx].IsRelational() {
+			continue
+		}
+		val, err := s.valueString(&attrs[idx], value)
+		if err != nil {
+			return "", err
+		}
+		vals = append(vals, strconv.Itoa(idx)+" "+val)
+	}
+	return "{" + strings.Join(vals, ",") + "}", nil
+}
+
+//Returns the value of the attribute as written in ARFF, quoted if needed
+func (s *ArffSaver) valueString(attr *Attribute, value float64) (string, error) {
+	if math.IsNaN(value) {
+		return ARFF_MISSING, nil
+	}
+	switch attr.Type() {
+	case NUMERIC:
+		return s.formatNumber(value), nil
+	case NOMINAL, STRING:
+		if int(value) < 0 || int(value) >= len(attr.Values()) {
+			return "", fmt.Errorf("value %v out of range for attribute '%s'", value, attr.Name())
+		}
+		return Quote(attr.Values()[int(value)]), nil
+	case DATE:
+		return Quote(attr.FormatDate(value)), nil
+	case RELATIONAL:
+		bag := attr.RelationValue(int(value))
+		rows := make([]string, len(bag.instances))
+		for i, inst := range bag.instances {
+			//the bag's instances are always dense
+			dense := *s
+			dense.options.Sparse = false
+			row, err := dense.instanceString(bag, inst)
+			if err != nil {
+				return "", err
 			}
+			rows[i] = row
 		}
-		tmp = strings.TrimSuffix(tmp, ",")
-		tmp += "}"
-		instDeclaration += "\n" + tmp
-	}
-	writer.WriteString(attrsDefinition)
-	writer.WriteString("\n@data")
-	writer.WriteString(instDeclaration)
-	writer.Flush()
+		return Quote(strings.Join(rows, "\n")), nil
+	}
+	return "", fmt.Errorf("cannot write value of attribute '%s' of type %d in ARFF", attr.Name(), attr.Type())
+}
+
+func (s *ArffSaver) formatNumber(value float64) string {
+	if s.options.MaxDecimalPlaces < 0 {
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+	formatted := strconv.FormatFloat(value, 'f', s.options.MaxDecimalPlaces, 64)
+	if strings.Contains(formatted, ".") {
+		formatted = strings.TrimRight(strings.TrimRight(formatted, "0"), ".")
+	}
+	return formatted
+}
+
+//Quotes a date format, formats such as "yyyy-MM-dd'T'HH:mm:ss" contain single
+//quotes so they are written in double quotes
+func quoteDateFormat(format string) string {
+	if strings.Contains(format, "'") && !strings.ContainsAny(format, "\"\\") {
+		return "\"" + format + "\""
+	}
+	return Quote(format)
+}
+
+//Gets methods
+
+func (s *ArffSaver) Header() Instances {
+	return s.header
+}
+
+func (s *ArffSaver) Options() ArffOptions {
+	return s.options
 }
@@ -0,0 +1,146 @@
+package data
+
+import (
+	"fmt"
+)
+
+//Immutable description of a dataset: relation name, attributes and class
+//index, like the header of weka's Instances. It holds copies of the attributes
+//and returns copies, so filters building their output from it do not share
+//values with their input and string values added to a dataset after the
+//header was taken do not change it. Instances and their rows share it by
+//reference, see Instances.Header and Instance.Header
+type Header struct {
+	relationName string
+	attributes   []Attribute
+	classIndex   int
+}
+
+//Creates a header from copies of the attributes, their indexes are set to
+//their positions. The class index is -1 if there is no class
+func NewHeader(relationName string, attributes []Attribute, classIndex int) *Header {
+	h := new(Header)
+	h.relationName = relationName
+	h.attributes = make([]Attribute, len(attributes))
+	for i := range attributes {
+		h.attributes[i] = attributes[i].Copy()
+		h.attributes[i].SetIndex(i)
+	}
+	h.classIndex = classIndex
+	return h
+}
+
+//Returns a header with the same attributes and another class index
+func (h *Header) WithClassIndex(classIndex int) *Header {
+	return NewHeader(h.relationName, h.attributes, classIndex)
+}
+
+//Returns an empty dataset with the header
+func (h *Header) EmptyInstances() Instances {
+	insts := NewInstancesWithClassIndex(h.classIndex)
+	insts.SetDatasetName(h.relationName)
+	insts.SetAttributes(h.Attributes())
+	return insts
+}
+
+//Returns the index of the attribute with the given name, -1 if there is none
+func (h *Header) AttributeIndex(name string) int {
+	for i := range h.attributes {
+		if h.attributes[i].Name() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+//Returns whether both headers have the same relation name, class index and
+//attributes
+func (h *Header) Equal(other *Header) bool {
+	return h.relationName == other.relationName && h.CheckCompatible(other) == nil
+}
+
+//Checks that data with the other header can be used with a model built on data
+//with this one, like weka's equalHeadersMsg: same class index and attributes
+//with the same names, types, nominal values and date formats. The relation
+//names and the values of string attributes are not compared. The error
+//describes the first difference found
+func (h *Header) CheckCompatible(other *Header) error {
+	if h == other {
+		return nil
+	}
+	if h.classIndex != other.classIndex {
+		return fmt.Errorf("Class index differ: %d != %d", h.classIndex+1, other.classIndex+1)
+	}
+	if len(h.attributes) != len(other.attributes) {
+		return fmt.Errorf("Different number of attributes: %d != %d", len(h.attributes), len(other.attributes))
+	}
+	for i := range h.attributes {
+		if err := compatibleAttributes(&h.attributes[i], &other.attributes[i]); err != nil {
+			return fmt.Errorf("Attributes differ at position %d: %s", i+1, err.Error())
+		}
+	}
+	return nil
+}
+
+//Checks that two attributes have the same name, type, nominal values, date
+//format and relational header
+func compatibleAttributes(a, b *Attribute) error {
+	if a.Name() != b.Name() {
+		return fmt.Errorf("Names differ: '%s' != '%s'", a.Name(), b.Name())
+	}
+	if a.Type() != b.Type() {
+		return fmt.Errorf("Types of '%s' differ: %d != %d", a.Name(), a.Type(), b.Type())
+	}
+	switch a.Type() {
+	case NOMINAL:
+		if len(a.Values()) != len(b.Values()) {
+			return fmt.Errorf("Different number of labels of '%s': %d != %d", a.Name(), len(a.Values()), len(b.Values()))
+		}
+		for i, value := range a.Values() {
+			if value != b.Values()[i] {
+				return fmt.Errorf("Labels of '%s' differ at position %d: '%s' != '%s'", a.Name(), i+1, value, b.Values()[i])
+			}
+		}
+	case DATE:
+		if a.DateFormat() != b.DateFormat() {
+			return fmt.Errorf("Date formats of '%s' differ: '%s' != '%s'", a.Name(), a.DateFormat(), b.DateFormat())
+		}
+	case RELATIONAL:
+		relA, relB := a.Relation(), b.Relation()
+		if relA == nil || relB == nil {
+			break
+		}
+		if err := NewHeader("", relA.Attributes(), relA.ClassIndex()).CheckCompatible(NewHeader("", relB.Attributes(), relB.ClassIndex())); err != nil {
+			return fmt.Errorf("Relational headers of '%s' differ: %s", a.Name(), err.Error())
+		}
+	}
+	return nil
+}
+
+//Gets methods
+
+func (h *Header) RelationName() string {
+	return h.relationName
+}
+
+func (h *Header) NumAttributes() int {
+	return len(h.attributes)
+}
+
+//Returns a copy of the attribute
+func (h *Header) Attribute(idx int) Attribute {
+	return h.attributes[idx].Copy()
+}
+
+//Returns copies of the attributes
+func (h *Header) Attributes() []Attribute {
+	attrs := make([]Attribute, len(h.attributes))
+	for i := range h.attributes {
+		attrs[i] = h.attributes[i].Copy()
+	}
+	return attrs
+}
+
+func (h *Header) ClassIndex() int {
+	return h.classIndex
+}
@@ -0,0 +1,65 @@
+package data
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const weatherARFF = `@relation weather
+@attribute outlook {sunny,overcast,rainy}
+@attribute temperature numeric
+@attribute humidity numeric
+@attribute windy {TRUE,FALSE}
+@attribute play {yes,no}
+@data
+sunny,85,85,FALSE,no
+sunny,80,90,TRUE,no
+overcast,83,86,FALSE,yes
+rainy,70,96,FALSE,yes
+rainy,68,80,FALSE,yes
+rainy,65,?,TRUE,no
+overcast,64,65,TRUE,yes
+sunny,72,95,FALSE,no
+sunny,69,70,FALSE,yes
+rainy,75,80,?,yes
+sunny,75,70,TRUE,yes
+overcast,0,90,TRUE,yes
+overcast,81,75,FALSE,yes
+rainy,71,91,TRUE,no
+`
+
+//Parses an ARFF dataset, the class is the last attribute
+func readTestARFF(t *testing.T, text string) Instances {
+	t.Helper()
+	insts, err := ReadARFF(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("ReadARFF: %v", err)
+	}
+	insts.SetClassIndex(len(insts.Attributes()) - 1)
+	return insts
+}
+
+//Returns the dataset written as dense ARFF, to compare datasets
+func arffString(t *testing.T, insts Instances) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := WriteARFF(&buf, insts, NewArffOptions()); err != nil {
+		t.Fatalf("WriteARFF: %v", err)
+	}
+	return buf.String()
+}
+
+//Dataset with string and date attributes, quoted values, an instance weight
+//and missing values
+const reviewsARFF = `@relation reviews
+@attribute text string
+@attribute stars numeric
+@attribute posted date "yyyy-MM-dd HH:mm:ss"
+@attribute label {pos,neg}
+@data
+'great, really',4.5,'2024-03-01 10:00:00',pos
+'it\'s awful',1,'2024-03-02 11:30:00',neg,{2}
+?,0,?,?
+'"quoted" word',3,'2024-03-05 08:30:00',pos
+`
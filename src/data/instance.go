@@ -2,242 +2,329 @@ package data
 
 import (
 	"math"
-	//"fmt"
 )
 
-//this struct is like a mix of weka's Instance and SparseInstance with my own implementation
-type Instance struct {
-	//Constants
-	MissingValue          float64
-	Input_Att, Output_Att int
-	//All values of the instance's attributes
-	values []string
-	//Stores only the nominal or strings attributes' values
-	//nominalStringValues[0] = input attributes, nominalStringValues[1] = output attributes
-	//nominalStringValues [][]string
-	//Stores the nominal or string attributes to the corresponding indexes in nominalStringValues
-	//intNominalStringValues[0] = input attributes, intNominalStringValues[1] = output attributes
-	//intNominalStringValues [][]int
-	//Stores the instance's attribute values //m_AttValues
-	realValues []float64
-	//Stores the missing values
-	//missingValues [][]bool
-	//Number of input attributes
-	//numInputAttrs int
-	//NUmber of output attributes
-	//numOutputAttrs int
-	//Stores the instance's attribute values
-	//floatValues []float64
-	//Instance's weight
+//A row of a dataset, like weka's Instance interface. Values are float64:
+//nominal, string and relational values are indexes into the attribute's
+//values and missing values are NaN. Positions refer to the stored values,
+//which are all the attributes' values for DenseInstance and only the non-zero
+//ones for SparseInstance, so both can be walked the same way:
+//
+//	for j := 0; j < inst.NumValues(); j++ {
+//		attIndex, value := inst.Index(j), inst.ValueSparse(j)
+//		...
+//	}
+type Instance interface {
+	//Returns the value of the attribute
+	Value(attIndex int) float64
+	//Returns the value stored at the position
+	ValueSparse(position int) float64
+	//Returns the index of the attribute whose value is stored at the position
+	Index(position int) int
+	//Returns the number of stored values
+	NumValues() int
+	NumAttributes() int
+	IsMissingValue(attIndex int) bool
+	IsMissingSparse(position int) bool
+	//Return the class value and whether it is missing, a negative class
+	//index (no class set) gives a missing class
+	ClassValue(classIndex int) float64
+	ClassMissing(classIndex int) bool
+	Weight() float64
+	SetWeight(weight float64)
+	//Sets the value of the attribute, in a sparse instance setting a value to
+	//zero removes it
+	SetValue(attIndex int, value float64)
+	SetValueSparse(position int, value float64)
+	IsSparse() bool
+	//Returns a copy that does not share its values with the instance, it
+	//shares the header
+	Copy() Instance
+	//Returns the header of the dataset the instance belongs to, nil if it
+	//was not added to one
+	Header() *Header
+	SetHeader(header *Header)
+}
+
+//Returns the value that represents a missing value
+func MissingValue() float64 {
+	return math.NaN()
+}
+
+//Returns whether any value of the instance is missing
+func hasMissingValue(instance Instance) bool {
+	for j := 0; j < instance.NumValues(); j++ {
+		if instance.IsMissingSparse(j) {
+			return true
+		}
+	}
+	return false
+}
+
+//Instance storing the values of all the attributes, like weka's DenseInstance
+type DenseInstance struct {
+	//The values by attribute index
+	values []float64
 	weight float64
-	//The index of the attribute associated with each stored value
-	indices []int
-	//The maximum number of values that can be stored
-	numAttributes int
+	header *Header
 }
 
-func NewInstance() Instance {
-	var inst Instance
-	inst.MissingValue = math.NaN()
-	inst.Input_Att = 0
-	inst.Output_Att = 1
-	inst.values = []string{}
-	//	inst.nominalStringValues = make([][]string, 2)
-	//	inst.intNominalStringValues = make([][]int, 2)
-	inst.realValues = []float64{}
-	//	inst.missingValues = make([][]bool, 2)
-	//	inst.numInputAttrs = 0
-	//	inst.numOutputAttrs = 0
-	inst.weight = 0.0
-	inst.indices = make([]int, 0)
-	inst.numAttributes = 0
+//Creates a dense instance with the given weight and values, one per
+//attribute. The slice is not copied
+func NewDenseInstance(weight float64, values []float64) *DenseInstance {
+	inst := new(DenseInstance)
+	inst.weight = weight
+	inst.values = values
 	return inst
 }
 
-func (i *Instance) Index(idx int) int {
-	return i.indices[idx]
+//Creates a dense instance with the values of another instance
+func NewDenseInstanceFrom(instance Instance) *DenseInstance {
+	values := make([]float64, instance.NumAttributes())
+	for j := 0; j < instance.NumValues(); j++ {
+		values[instance.Index(j)] = instance.ValueSparse(j)
+	}
+	return NewDenseInstance(instance.Weight(), values)
 }
 
-func (i *Instance) ValueSparse(idx int) float64 {
-	return i.realValues[idx]
+func (i *DenseInstance) Value(attIndex int) float64 {
+	return i.values[attIndex]
 }
 
-//for sparse instances only
-func (i *Instance) ClassValue(classIndex int) float64 {
-	if classIndex < 0 {
-		panic("Class is not set")
-	}
-	index := i.findIndex(classIndex)
-	if (index >= 0) && (i.indices[index]  == classIndex) {
-		return i.realValues[index]	
-	}
-	return 0.0
+func (i *DenseInstance) ValueSparse(position int) float64 {
+	return i.values[position]
+}
+
+func (i *DenseInstance) Index(position int) int {
+	return position
+}
+
+func (i *DenseInstance) NumValues() int {
+	return len(i.values)
+}
+
+func (i *DenseInstance) NumAttributes() int {
+	return len(i.values)
 }
 
-func (i *Instance) Value(idx int) float64 {
-	index := i.findIndex(idx)
-	if (index >= 0) && (i.indices[index]  == idx) {
-		return i.realValues[index]	
+func (i *DenseInstance) IsMissingValue(attIndex int) bool {
+	return math.IsNaN(i.values[attIndex])
+}
+
+func (i *DenseInstance) IsMissingSparse(position int) bool {
+	return math.IsNaN(i.values[position])
+}
+
+func (i *DenseInstance) ClassValue(classIndex int) float64 {
+	if classIndex < 0 {
+		return MissingValue()
 	}
-	return 0.0
+	return i.values[classIndex]
 }
 
-func (i *Instance) ClassMissing(classIndex int) bool {
+func (i *DenseInstance) ClassMissing(classIndex int) bool {
 	if classIndex < 0 {
-		panic("Class is not set")
+		return true
 	}
 	return i.IsMissingValue(classIndex)
 }
 
-func (i *Instance) IsMissingValue(idx int) bool {
-	return i.realValues[idx] == math.NaN()
+func (i *DenseInstance) Weight() float64 {
+	return i.weight
 }
 
-func (i *Instance) AddValues(value string) {
-	i.values = append(i.values, value)
-	//fmt.Println(i.values)
+func (i *DenseInstance) SetWeight(weight float64) {
+	i.weight = weight
 }
-//for sparse instances only
-func (i *Instance) findIndex(index int) int {
-	min := 0
-	max := len(i.indices) - 1
-	if max == -1 {
-		return -1
-	}
-	//Binary search
-	for (i.indices[min] <= index) && (i.indices[max] >= index) {
-		current := (max + min) / 2
-		if i.indices[current] > index {
-			max = current - 1
-		} else if i.indices[current] < index {
-			min = current + 1
-		} else {
-			return current
-		}
-	}
-	if i.indices[max] < index {
-		return max
-	} else {
-		return min - 1
-	}
+
+func (i *DenseInstance) SetValue(attIndex int, value float64) {
+	i.values[attIndex] = value
 }
 
-//func (i *Instance) AddValuesWithIndex(idx int, value string) {
-//	i.values[idx] = value
-//}
-//
-//func (i *Instance) AddNominalStringValues(direction int, idx int, value string) {
-//	if len(i.nominalStringValues[direction]) <= 0 {
-//		i.nominalStringValues[direction] = make([]string, idx)
-//	}
-//	i.nominalStringValues[direction][idx] = value
-//}
-//
-//func (i *Instance) AddIntNominalStringValues(direction int, idx int, value int) {
-//	i.intNominalStringValues[direction][idx] = value
-//}
+func (i *DenseInstance) SetValueSparse(position int, value float64) {
+	i.values[position] = value
+}
 
-func (i *Instance) AddRealValues(value float64) {
-	i.realValues = append(i.realValues, value)
+func (i *DenseInstance) IsSparse() bool {
+	return false
 }
 
-func (i *Instance) AddRealValuesIndex(idx int, value float64) {
-	i.realValues[idx] = value
+func (i *DenseInstance) Copy() Instance {
+	values := make([]float64, len(i.values))
+	copy(values, i.values)
+	inst := NewDenseInstance(i.weight, values)
+	inst.header = i.header
+	return inst
 }
 
-//func (i *Instance) AddMissingValues(direction int, idx int, value bool) {
-//	i.missingValues[direction][idx] = value
-//}
+func (i *DenseInstance) Header() *Header {
+	return i.header
+}
 
-func (i *Instance) AddIndices(idx int) {
-	i.indices = append(i.indices, idx)
+func (i *DenseInstance) SetHeader(header *Header) {
+	i.header = header
+}
+
+//Instance storing only the non-zero values, like weka's SparseInstance.
+//Missing values are stored as they are not zero
+type SparseInstance struct {
+	//The stored values
+	values []float64
+	//The index of the attribute associated with each stored value, ascending
+	indices []int
+	//The number of attributes of the dataset
+	numAttributes int
+	weight float64
+	header *Header
 }
 
-//Gets methods
+//Creates a sparse instance with the given weight, stored values and their
+//attribute indexes, which must be in ascending order. The slices are not
+//copied
+func NewSparseInstance(weight float64, values []float64, indices []int, numAttributes int) *SparseInstance {
+	inst := new(SparseInstance)
+	inst.weight = weight
+	inst.values = values
+	inst.indices = indices
+	inst.numAttributes = numAttributes
+	return inst
+}
 
-func (i *Instance) Indices() []int {
-	return i.indices
+//Creates a sparse instance with the non-zero values of another instance
+func NewSparseInstanceFrom(instance Instance) *SparseInstance {
+	values := make([]float64, 0, instance.NumValues())
+	indices := make([]int, 0, instance.NumValues())
+	for j := 0; j < instance.NumValues(); j++ {
+		if value := instance.ValueSparse(j); value != 0 {
+			values = append(values, value)
+			indices = append(indices, instance.Index(j))
+		}
+	}
+	return NewSparseInstance(instance.Weight(), values, indices, instance.NumAttributes())
 }
 
-func (i *Instance) NumAttributes() int {
-	if i.numAttributes > len(i.realValues) {
-		return len(i.realValues)
+func (i *SparseInstance) Value(attIndex int) float64 {
+	position := i.locateIndex(attIndex)
+	if position >= 0 && i.indices[position] == attIndex {
+		return i.values[position]
 	}
+	return 0.0
+}
+
+func (i *SparseInstance) ValueSparse(position int) float64 {
+	return i.values[position]
+}
+
+func (i *SparseInstance) Index(position int) int {
+	return i.indices[position]
+}
+
+func (i *SparseInstance) NumValues() int {
+	return len(i.values)
+}
+
+func (i *SparseInstance) NumAttributes() int {
 	return i.numAttributes
 }
 
-func (i *Instance) Values() []string {
-	return i.values
+func (i *SparseInstance) IsMissingValue(attIndex int) bool {
+	return math.IsNaN(i.Value(attIndex))
 }
 
-//func (i *Instance) NominalStringValues() [][]string {
-//	return i.nominalStringValues
-//}
-//
-//func (i *Instance) IntNominalStringValues() [][]int {
-//	return i.intNominalStringValues
-//}
-//
-func (i *Instance) RealValues() []float64 {
-	return i.realValues
+func (i *SparseInstance) IsMissingSparse(position int) bool {
+	return math.IsNaN(i.values[position])
 }
 
-//
-//func (i *Instance) MissingValues() [][]bool {
-//	return i.missingValues
-//}
-//
-//func (i *Instance) NumOutputAttrs() int {
-//	return i.numOutputAttrs
-//}
-//
-//func (i *Instance) NumInputAttrs() int {
-//	return i.numInputAttrs
-//}
+func (i *SparseInstance) ClassValue(classIndex int) float64 {
+	if classIndex < 0 {
+		return MissingValue()
+	}
+	return i.Value(classIndex)
+}
 
-func (i *Instance) Weight() float64 {
+func (i *SparseInstance) ClassMissing(classIndex int) bool {
+	if classIndex < 0 {
+		return true
+	}
+	return i.IsMissingValue(classIndex)
+}
+
+func (i *SparseInstance) Weight() float64 {
 	return i.weight
 }
 
-//Sets methods
+func (i *SparseInstance) SetWeight(weight float64) {
+	i.weight = weight
+}
 
-func (i *Instance) SetIndices(indices []int) {
-	i.indices = indices
+func (i *SparseInstance) SetValue(attIndex int, value float64) {
+	position := i.locateIndex(attIndex)
+	if position >= 0 && i.indices[position] == attIndex {
+		if value != 0 {
+			i.values[position] = value
+		} else {
+			i.values = append(i.values[:position], i.values[position+1:]...)
+			i.indices = append(i.indices[:position], i.indices[position+1:]...)
+		}
+	} else if value != 0 {
+		position++
+		i.values = append(i.values, 0)
+		copy(i.values[position+1:], i.values[position:])
+		i.values[position] = value
+		i.indices = append(i.indices, 0)
+		copy(i.indices[position+1:], i.indices[position:])
+		i.indices[position] = attIndex
+	}
 }
 
-func (i *Instance) SetNumAttributes(numAttrs int) {
-	i.numAttributes = numAttrs
+func (i *SparseInstance) SetValueSparse(position int, value float64) {
+	i.values[position] = value
 }
 
-func (i *Instance) SetValues(values []string) {
-	i.values = values
+func (i *SparseInstance) IsSparse() bool {
+	return true
 }
 
-//func (i *Instance) SetNominalStringValues(nsv [][]string) {
-//	i.nominalStringValues = nsv
-//}
-//
-//func (i *Instance) SetIntNominalStringValues(insv [][]int) {
-//	i.intNominalStringValues = insv
-//}
-//
-func (i *Instance) SetRealValues(realValues []float64) {
-	i.realValues = realValues
+func (i *SparseInstance) Copy() Instance {
+	values := make([]float64, len(i.values))
+	copy(values, i.values)
+	indices := make([]int, len(i.indices))
+	copy(indices, i.indices)
+	inst := NewSparseInstance(i.weight, values, indices, i.numAttributes)
+	inst.header = i.header
+	return inst
 }
 
-//
-//func (i *Instance) SetMissingValues(missing [][]bool) {
-//	i.missingValues = missing
-//}
-//
-//func (i *Instance) SetNumOutputAttrs(noa int) {
-//	i.numOutputAttrs = noa
-//}
-//
-//func (i *Instance) SetNumInputAttrs(niu int) {
-//	i.numInputAttrs = niu
-//}
+func (i *SparseInstance) Header() *Header {
+	return i.header
+}
 
-func (i *Instance) SetWeight(weight float64) {
-	i.weight = weight
+func (i *SparseInstance) SetHeader(header *Header) {
+	i.header = header
+}
+
+//Returns the position of the attribute's value, or of the last value before
+//it if it is not stored (-1 if there is none)
+func (i *SparseInstance) locateIndex(attIndex int) int {
+	min := 0
+	max := len(i.indices) - 1
+	if max == -1 {
+		return -1
+	}
+	//Binary search
+	for (i.indices[min] <= attIndex) && (i.indices[max] >= attIndex) {
+		current := (max + min) / 2
+		if i.indices[current] > attIndex {
+			max = current - 1
+		} else if i.indices[current] < attIndex {
+			min = current + 1
+		} else {
+			return current
+		}
+	}
+	if i.indices[max] < attIndex {
+		return max
+	} else {
+		return min - 1
+	}
 }
@@ -0,0 +1,101 @@
+package data
+
+import (
+	"math"
+	"testing"
+)
+
+func TestClassNotSet(t *testing.T) {
+	insts := readTestARFF(t, weatherARFF)
+	csr, err := NewInstancesCSR(insts)
+	if err != nil {
+		t.Fatalf("NewInstancesCSR: %v", err)
+	}
+	instances := []Instance{
+		insts.Instance(0),
+		NewSparseInstance(1.0, []float64{1}, []int{0}, 5),
+		csr.Instance(0),
+	}
+	for _, inst := range instances {
+		if !math.IsNaN(inst.ClassValue(-1)) || !inst.ClassMissing(-1) {
+			t.Errorf("%T: class with index -1 is not missing", inst)
+		}
+	}
+	insts.SetClassIndex(-1)
+	if err := insts.Stratify(3); err != ErrClassNotSet {
+		t.Errorf("Stratify without class: got %v", err)
+	}
+}
+
+func TestLocateIndex(t *testing.T) {
+	inst := NewSparseInstance(1.0, []float64{1, 2, 3, 4}, []int{1, 3, 4, 8}, 10)
+	for attIndex := 0; attIndex < 10; attIndex++ {
+		//the position of the last stored index not greater than attIndex
+		want := -1
+		for position, index := range inst.indices {
+			if index <= attIndex {
+				want = position
+			}
+		}
+		if got := inst.locateIndex(attIndex); got != want {
+			t.Errorf("locateIndex(%d) = %d, want %d", attIndex, got, want)
+		}
+	}
+	if got := NewSparseInstance(1.0, nil, nil, 3).locateIndex(1); got != -1 {
+		t.Errorf("locateIndex in an empty instance = %d, want -1", got)
+	}
+}
+
+func TestDenseAndSparseInstances(t *testing.T) {
+	values := []float64{0, 2.5, 0, MissingValue(), 0, 7}
+	dense := NewDenseInstance(2.0, values)
+	sparse := NewSparseInstanceFrom(dense)
+	if sparse.NumValues() != 3 || sparse.NumAttributes() != 6 || sparse.Weight() != 2 {
+		t.Fatalf("sparse instance stores %d of %d values with weight %v, want 3 of 6 with weight 2", sparse.NumValues(), sparse.NumAttributes(), sparse.Weight())
+	}
+	//the missing value is stored, it is not zero
+	for position, index := range []int{1, 3, 5} {
+		if sparse.Index(position) != index || sparse.IsMissingSparse(position) != (index == 3) {
+			t.Errorf("stored value %d has index %d and missing %v", position, sparse.Index(position), sparse.IsMissingSparse(position))
+		}
+	}
+	if dense.NumValues() != 6 || dense.Index(4) != 4 || dense.ValueSparse(5) != 7 {
+		t.Errorf("dense instance has %d values, index %d and value %v", dense.NumValues(), dense.Index(4), dense.ValueSparse(5))
+	}
+	back := NewDenseInstanceFrom(sparse)
+	for j := range values {
+		for _, inst := range []Instance{dense, sparse, back} {
+			if got := inst.Value(j); got != values[j] && !(math.IsNaN(got) && math.IsNaN(values[j])) {
+				t.Errorf("%T: value %d = %v, want %v", inst, j, got, values[j])
+			}
+			if inst.IsMissingValue(j) != (j == 3) {
+				t.Errorf("%T: value %d missing %v", inst, j, inst.IsMissingValue(j))
+			}
+		}
+	}
+	if back.IsSparse() || !sparse.IsSparse() || back.Weight() != 2 {
+		t.Errorf("converted instances are sparse %v and %v with weight %v", back.IsSparse(), sparse.IsSparse(), back.Weight())
+	}
+}
+
+func TestSparseSetValue(t *testing.T) {
+	inst := NewSparseInstance(1.0, []float64{1, 3}, []int{1, 3}, 6)
+	copied := inst.Copy()
+	inst.SetValue(0, 5)
+	inst.SetValue(4, 6)
+	inst.SetValue(3, 0)
+	inst.SetValue(1, 2)
+	inst.SetValue(2, 0)
+	want := []float64{5, 2, 0, 0, 6, 0}
+	for j, value := range want {
+		if got := inst.Value(j); got != value {
+			t.Errorf("value %d = %v, want %v", j, got, value)
+		}
+	}
+	if inst.NumValues() != 3 || inst.Index(0) != 0 || inst.Index(1) != 1 || inst.Index(2) != 4 {
+		t.Errorf("stored indices %v", inst.indices)
+	}
+	if copied.Value(0) != 0 || copied.Value(3) != 3 || copied.NumValues() != 2 {
+		t.Errorf("setting values changed the copy")
+	}
+}
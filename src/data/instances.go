@@ -1,18 +1,15 @@
 package data
 
 import (
-	"bufio"
-	"bytes"
-	"encoding/csv"
 	"fmt"
 	"math/rand"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
-var _attributes = make([]Attribute, 0)
-
 type Instances struct {
 	//Dataset's name
 	datasetName string
@@ -22,6 +19,16 @@ type Instances struct {
 	attributes []Attribute
 	//Class attribute's index
 	classIndex int
+	//Whether any instance has a missing value
+	hasMissing bool
+	//Header shared with the instances, nil until it is needed and after
+	//changes to the attributes
+	header *Header
+	//Matrix holding the instances, nil if they are not stored in one
+	csr *CSRMatrix
+	//Indexes of the output attributes of a multi-target dataset, in
+	//ascending order, nil if the output is the class attribute
+	outputs []int
 }
 
 func NewInstances() Instances {
@@ -38,6 +45,10 @@ func NewInstancesWithInst(inst Instances, capacity int) Instances {
 	}
 	i.datasetName = inst.DatasetName()
 	i.attributes = inst.Attributes()
+	i.outputs = inst.outputs
+	if i.classIndex == inst.classIndex {
+		i.header = inst.header
+	}
 	return i
 }
 
@@ -48,242 +59,243 @@ func NewInstancesWithClassIndex(classIndex int) Instances {
 	return inst
 }
 
+//Returns the attribute, it must not be changed through the pointer as the
+//header of the instances would not see it, use RenameAttribute,
+//RenameAttributeValue or SetAttributeWeight
 func (i *Instances) Attribute(idx int) *Attribute {
 	return &i.attributes[idx]
 }
 
-func (i *Instances) Instance(idx int) *Instance {
-	return &i.instances[idx]
+func (i *Instances) Instance(idx int) Instance {
+	return i.instances[idx]
 }
 
-//Parse file dataset
+//Parse file dataset, errors in the file are returned as *ParseError
 func (inst *Instances) ParseFile(filepath string) error {
-	inst.processHeader(filepath)
-	inst.parseInstances(filepath)
-	return nil
-}
-
-//Process att
-func (inst *Instances) processHeader(filepath string) error {
 	file, err := os.Open(filepath)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
-	fmt.Println("Processing attributes definition in file: " + file.Name())
-	reader := bufio.NewScanner(file)
-	attrIndex := 0
-	for reader.Scan() {
-		line := reader.Text()
-		if !strings.HasPrefix(line, "@data") {
-			if strings.HasPrefix(line, "@relation") {
-				//fmt.Println("Reading @relation field")
-				inst.SetDatasetName(strings.TrimPrefix(line, "@relation "))
-				inst.SetDatasetName(strings.TrimPrefix(line, "@relation "))
-			} else if strings.HasPrefix(line, "@attribute") {
-				//fmt.Println("Reading ", "@attribute", " field")
-				inst.parseAttribute(line, attrIndex)
-				attrIndex++
-			}
-		}
-		inst.attributes = _attributes
-		//var att Attribute
-	}
-	return nil
+	return inst.Read(file)
 }
 
-func (inst *Instances) parseAttribute(line string, attrIndex int) {
-	fields := strings.Fields(strings.ToLower(strings.TrimPrefix(line, "@attribute")))
-	//fmt.Println(len(fields))
-	//fmt.Println(fields[0])
-	if len(fields) < 2 {
-		panic("Attribute's name is not defined, check your dataset.")
-	}
-	//	if len(fields) < 3 {
-	//		panic("Attribute's type is not defined, check your dataset.")
-	//	}
-	name := fields[0]
+//Parses an attribute declaration, the attribute is the attrIndex-th of inst
+func (inst *Instances) parseAttribute(line string, attrIndex int) (Attribute, error) {
 	attr := NewAttribute()
-	attr.SetName(name)
-	attr_type := fields[1]
+	tokens, err := headerTokens(line)
+	if err != nil {
+		return attr, err
+	}
+	if len(tokens) < 2 {
+		return attr, fmt.Errorf("Attribute's name is not defined, check your dataset.")
+	}
+	if len(tokens) < 3 {
+		return attr, fmt.Errorf("Attribute's type is not defined for '%s'", tokens[1])
+	}
+	attr.SetName(tokens[1])
+	attr.SetIndex(attrIndex)
+	if attrIndex == inst.classIndex {
+		attr.SetDirection(OUTPUT)
+	} else {
+		attr.SetDirection(INPUT)
+	}
+	attr_type := strings.ToLower(tokens[2])
 	if attr_type == attr.Arff_Integer || attr_type == attr.Arff_Numeric || attr_type == attr.Arff_Real {
 		//Parse numeric attribute
-		//fmt.Println("parsing numeric attribute")
 		attr.SetType(NUMERIC)
-		if strings.Contains(line, "[") && strings.Contains(line, "]") {
-			//fmt.Println("parsing bounds")
-			bounds := line[strings.Index(line, "[")+1 : strings.Index(line, "]")] //example: "[23, 89]", bounds = "23, 89"
-			//fmt.Println("bounds: ", bounds)
-			min := strings.TrimSpace(bounds[:strings.Index(bounds, ",")])
-			//fmt.Println("min: ", min)
-			max := strings.TrimSpace(bounds[strings.Index(bounds, ",")+1:])
-			//fmt.Println("max: ", max)
-			min_float, err := strconv.ParseFloat(min, 64)
+		attr.SetHasFixedBounds(false)
+		rest := strings.Join(tokens[3:], " ")
+		if strings.HasPrefix(rest, "[") {
+			//example: "[23, 89]", bounds = "23, 89", the closing bracket is optional
+			bounds := strings.TrimSuffix(rest[1:], "]")
+			sep := strings.Index(bounds, ",")
+			if sep < 0 {
+				return attr, fmt.Errorf("bad bounds declaration '%s' for '%s'", rest, attr.Name())
+			}
+			min_float, err := strconv.ParseFloat(strings.TrimSpace(bounds[:sep]), 64)
 			if err != nil {
-				panic(fmt.Errorf("Impossible to cast from string to float, bad bounds declaration in min at line '%s'", line))
+				return attr, fmt.Errorf("Impossible to cast from string to float, bad bounds declaration in min for '%s'", attr.Name())
 			}
-			attr.SetMin(min_float)
-			max_float, err := strconv.ParseFloat(max, 64)
+			max_float, err := strconv.ParseFloat(strings.TrimSpace(bounds[sep+1:]), 64)
 			if err != nil {
-				panic(fmt.Errorf("Impossible to cast from string to float, bad bounds declaration in max at line '%s'", line))
+				return attr, fmt.Errorf("Impossible to cast from string to float, bad bounds declaration in max for '%s'", attr.Name())
 			}
+			attr.SetMin(min_float)
 			attr.SetMax(max_float)
 			attr.SetHasFixedBounds(true)
-		} else {
-			attr.SetHasFixedBounds(false)
-		}
-		attr.SetIndex(attrIndex)
-		if attrIndex == inst.classIndex {
-			attr.SetDirection(1)
-		} else {
-			attr.SetDirection(0)
+		} else if rest != "" {
+			return attr, fmt.Errorf("unexpected '%s' after the type of '%s'", rest, attr.Name())
 		}
 	} else if attr_type == attr.Arff_String {
 		//Parse string attribute
-		//fmt.Println("parsing string attribute")
 		attr.SetType(STRING)
-		attr.SetIndex(attrIndex)
-		if attrIndex == inst.classIndex {
-			attr.SetDirection(1)
-		} else {
-			attr.SetDirection(0)
+	} else if attr_type == attr.Arff_Date {
+		//Parse date attribute, the format is optional
+		attr.SetType(DATE)
+		format := DEFAULT_DATE_FORMAT
+		if len(tokens) > 3 {
+			format = tokens[3]
 		}
-	} else if strings.Contains(line, "{") && strings.Contains(line, "}") {
+		if len(tokens) > 4 {
+			return attr, fmt.Errorf("unexpected '%s' after the date format of '%s'", tokens[4], attr.Name())
+		}
+		if err := attr.SetDateFormat(format); err != nil {
+			return attr, err
+		}
+	} else if attr_type == attr.Arff_Relational {
+		//The inner attributes follow until the @end declaration
+		attr.SetType(RELATIONAL)
+		relation := NewInstancesWithClassIndex(-1)
+		relation.SetDatasetName(attr.Name())
+		relation.attributes = make([]Attribute, 0)
+		attr.SetRelation(&relation)
+	} else if strings.HasPrefix(tokens[2], "{") {
 		//is nominal attribute
-		//fmt.Println("parsing nominal attribute")
 		attr.SetType(NOMINAL)
 		attr.SetHasFixedBounds(true)
-		nominalValues(line[strings.Index(line, "{")+1:strings.Index(line, "}")], &attr)
-		if attrIndex == inst.classIndex {
-			attr.SetDirection(1)
-		} else {
-			attr.SetDirection(0)
+		if err := nominalValues(tokens[2], &attr); err != nil {
+			return attr, err
 		}
 	} else {
-		panic(fmt.Errorf("Unsupported attribute type '%s' or bad nominal attribute definition", attr_type))
+		return attr, fmt.Errorf("Unsupported attribute type '%s' or bad nominal attribute definition", tokens[2])
 	}
-	_attributes = append(_attributes, attr)
+	return attr, nil
 }
 
-func nominalValues(line string, attr *Attribute) {
-	//fmt.Println(line)
-	line = strings.TrimSpace(strings.Replace(strings.Replace(line, " ", "", -1), ",", " ", -1))
-	vals := strings.Fields(line)
+func nominalValues(spec string, attr *Attribute) error {
+	vals, err := nominalSpec(spec)
+	if err != nil {
+		return err
+	}
 	valuesIndexes := make(map[string]int)
 	values := make([]string, len(vals))
 	for index, value := range vals {
+		if _, present := valuesIndexes[value]; present {
+			return fmt.Errorf("duplicate nominal value '%s' for '%s'", value, attr.Name())
+		}
 		valuesIndexes[value] = index
 		values[index] = value
 	}
-	//fmt.Println(values)
 	attr.SetValues(values)
 	attr.SetValuesIndexes(valuesIndexes)
+	return nil
 }
 
-//func (i *Instances) populateAttributes() {
-//	attrs := NewAttributes()
-//	for _, attribute := range _attributes {
-//		attrs.AddAttribute(attribute)
-//	}
-//	attrs.SetTotalAttrs(len(attrs.Attributes()))
-//	i.SetAttributes(attrs)
-//}
-
-func (inst *Instances) parseInstances(filepath string) error {
-	file, err := os.Open(filepath)
+//Parses a dense or sparse data row with an optional {weight} suffix
+func (inst *Instances) parseInstance(line string) (Instance, error) {
+	fields, sparse, weightField, err := splitInstanceLine(line)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer file.Close()
-	//fmt.Println("Parsing instances.")
-	reader := bufio.NewScanner(file)
-	for reader.Scan() {
-		line := reader.Text()
-		//Go down the file until the beginning of instances definitions
-		if strings.HasPrefix(line, "@data") {
-			break
+	weight := 1.0
+	if weightField != "" {
+		weight, err = strconv.ParseFloat(weightField, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad instance weight '%s'", weightField)
 		}
 	}
-	for reader.Scan() {
-		line := reader.Text()
-		if len(line) == 0 {
-			continue
+	if sparse {
+		values := make([]float64, 0, len(fields))
+		indices := make([]int, 0, len(fields))
+		last := -1
+		for _, field := range fields {
+			field = strings.TrimSpace(field)
+			sep := strings.IndexFunc(field, unicode.IsSpace)
+			if sep < 0 {
+				return nil, fmt.Errorf("sparse value '%s' must be 'index value'", field)
+			}
+			idx, err := strconv.Atoi(field[:sep])
+			if err != nil || idx < 0 || idx >= len(inst.attributes) {
+				return nil, fmt.Errorf("bad attribute index '%s'", field[:sep])
+			}
+			if idx <= last {
+				return nil, fmt.Errorf("attribute indexes must be in ascending order, found %d after %d", idx, last)
+			}
+			last = idx
+			val, quoted, err := unquote(field[sep:])
+			if err != nil {
+				return nil, err
+			}
+			value, err := inst.parseValue(&inst.attributes[idx], val, quoted)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+			indices = append(indices, idx)
 		}
-		//fmt.Println(line)
-		instance := NewInstance()
-		//make sure the instance is well-read
-		line = strings.TrimSpace(line)
-		//attributes' values first as strings
-		buff := bytes.NewBuffer([]byte(line))
-		csvReader := csv.NewReader(buff)
-		attVals, err := csvReader.Read()
+		return NewSparseInstance(weight, values, indices, len(inst.attributes)), nil
+	}
+	if len(fields) != len(inst.attributes) {
+		return nil, fmt.Errorf("found %d values, expected %d", len(fields), len(inst.attributes))
+	}
+	values := make([]float64, len(fields))
+	for idx, field := range fields {
+		val, quoted, err := unquote(field)
 		if err != nil {
-			panic(fmt.Errorf("Malformed instance in line %s", line))
+			return nil, err
 		}
-		for idx, val := range attVals {
-			//fmt.Printf("Index: %d, Value: %s", idx, val)
-			//fmt.Println()
-			attr := &inst.attributes[idx]
-			direction := attr.Direction()
-			//instance.AddValues(val)
-			inst.readValue(attr, direction, val, idx, &instance)
+		values[idx], err = inst.parseValue(&inst.attributes[idx], val, quoted)
+		if err != nil {
+			return nil, err
 		}
-		instance.SetWeight(1.0)
-		instance.SetNumAttributes(len(instance.Values()))
-		inst.instances = append(inst.instances, instance)
-		//fmt.Println(line)
-		fmt.Println(instance.NumAttributes())
 	}
-	return nil
+	return NewDenseInstance(weight, values), nil
 }
 
-func (inst *Instances) readValue(attr *Attribute, direction int, val string, idx int, instance *Instance) {
-	if strings.EqualFold(val, "<null>") || strings.EqualFold(val, "?") {
-
-		switch attr.Type() {
-		case NUMERIC:
-			instance.AddValues(val)
-			instance.AddRealValues(instance.MissingValue)
-			break
-		case NOMINAL:
-		case STRING:
-			instance.AddValues(val)
-			instance.AddRealValues(instance.MissingValue)
-			break
+//Returns the internal value of the attribute's value, unquoted ? is missing.
+//String and relational values are added to the attribute
+func (inst *Instances) parseValue(attr *Attribute, val string, quoted bool) (float64, error) {
+	if !quoted && (val == ARFF_MISSING || strings.EqualFold(val, "<null>")) {
+		inst.hasMissing = true
+		return MissingValue(), nil
+	}
+	switch attr.Type() {
+	case NUMERIC:
+		value, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return 0, fmt.Errorf("bad numeric value '%s' for '%s'", val, attr.Name())
 		}
-	} else {
-		switch attr.Type() {
-		case NUMERIC:
-			value, _ := strconv.ParseFloat(val, 64)
-			instance.AddValues(val)
-			instance.AddRealValues(value)
-			break
-		case NOMINAL:
-			indx := inst.attributes[idx].ValuesIndexes()[val]
-			instance.AddRealValues(float64(indx))
-			instance.AddValues(val)
-			break
-		case STRING:
-			//fmt.Println(" String in readValue")
-			val = strings.Trim(val, "'")
-			instance.AddValues(val)
-			instance.AddRealValues(float64(attr.AddStringValue(val)))
-			//fmt.Println(val)
-			break
+		return value, nil
+	case NOMINAL:
+		indx, present := attr.ValuesIndexes()[val]
+		if !present {
+			return 0, fmt.Errorf("nominal value '%s' not declared for '%s'", val, attr.Name())
 		}
+		return float64(indx), nil
+	case STRING:
+		inst.header = nil
+		return float64(attr.AddStringValue(val)), nil
+	case DATE:
+		return attr.ParseDate(val)
+	case RELATIONAL:
+		//the bag's instances are given one per line in a quoted string
+		bag := NewInstancesWithInst(*attr.Relation(), 0)
+		for _, line := range strings.Split(val, "\n") {
+			line = strings.TrimSpace(stripComment(line))
+			if line == "" {
+				continue
+			}
+			row, err := bag.parseInstance(line)
+			if err != nil {
+				return 0, fmt.Errorf("bad value for relational attribute '%s': %s", attr.Name(), err.Error())
+			}
+			bag.instances = append(bag.instances, row)
+		}
+		bag.shareHeader()
+		inst.header = nil
+		return float64(attr.AddRelation(bag)), nil
 	}
-	//fmt.Println(attr.Values())
+	return 0, fmt.Errorf("attribute '%s' has unknown type %d", attr.Name(), attr.Type())
 }
 
-//Creates the training set for one fold of a cross-validation on the dataset
-func (i *Instances) TrainCV(numFolds, numFold, seed int) Instances {
+//Creates the training set for one fold of a cross-validation on the dataset,
+//it shares the instances with the dataset
+func (i *Instances) TrainCV(numFolds, numFold, seed int) (Instances, error) {
 	var numInstForFold, first, offset int
 	var train Instances
-	if numFolds < 2 {
-		panic("The number of folds should be at least 2 or more.")
-	}
-	if numFolds > len(i.instances) {
-		panic("The number of folds can't be greater than number of instances")
+	if err := i.checkFolds(numFolds, numFold); err != nil {
+		return train, err
 	}
 	numInstForFold = len(i.instances) / numFolds
 	if numFold < len(i.instances)%numFolds {
@@ -292,27 +304,120 @@ func (i *Instances) TrainCV(numFolds, numFold, seed int) Instances {
 	} else {
 		offset = len(i.instances) % numFolds
 	}
+	//the folds take the header of the dataset, so they can share its instances
+	i.Header()
 	train = NewInstancesWithInst(*i, len(i.instances)-numInstForFold)
 	first = numFold*(len(i.instances)/numFolds) + offset
 	i.copyInstances(0, &train, first)
 	i.copyInstances(first+numInstForFold, &train, len(i.instances)-first-numInstForFold)
 	train.Randomize(seed)
-	return train
+	return train, nil
 }
 
-//Copies instances from one set to the end of another one
+//Creates the test set for one fold of a cross-validation on the dataset, it
+//shares the instances with the dataset
+func (i *Instances) TestCV(numFolds, numFold int) (Instances, error) {
+	var numInstForFold, first, offset int
+	var test Instances
+	if err := i.checkFolds(numFolds, numFold); err != nil {
+		return test, err
+	}
+	numInstForFold = len(i.instances) / numFolds
+	if numFold < len(i.instances)%numFolds {
+		numInstForFold++
+		offset = numFold
+	} else {
+		offset = len(i.instances) % numFolds
+	}
+	//the folds take the header of the dataset, so they can share its instances
+	i.Header()
+	test = NewInstancesWithInst(*i, numInstForFold)
+	first = numFold*(len(i.instances)/numFolds) + offset
+	i.copyInstances(first, &test, numInstForFold)
+	return test, nil
+}
+
+//Checks the arguments of a cross-validation split
+func (i *Instances) checkFolds(numFolds, numFold int) error {
+	if numFolds < 2 {
+		return fmt.Errorf("The number of folds should be at least 2 or more.")
+	}
+	if numFolds > len(i.instances) {
+		return fmt.Errorf("The number of folds can't be greater than number of instances")
+	}
+	if numFold < 0 || numFold >= numFolds {
+		return fmt.Errorf("Fold %d does not exist, there are %d folds", numFold, numFolds)
+	}
+	return nil
+}
+
+//Stratifies a set of instances according to its class values if the class
+//attribute is nominal (so that afterwards a stratified cross-validation can
+//be performed)
+func (i *Instances) Stratify(numFolds int) error {
+	if numFolds <= 1 {
+		return fmt.Errorf("Number of folds must be greater than 1")
+	}
+	if i.classIndex < 0 {
+		return ErrClassNotSet
+	}
+	if !i.attributes[i.classIndex].IsNominal() {
+		return nil
+	}
+	// sort by class
+	index := 1
+	for index < len(i.instances) {
+		inst1 := i.Instance(index - 1)
+		for j := index; j < len(i.instances); j++ {
+			inst2 := i.Instance(j)
+			if inst1.ClassValue(i.classIndex) == inst2.ClassValue(i.classIndex) ||
+				(inst1.ClassMissing(i.classIndex) && inst2.ClassMissing(i.classIndex)) {
+				i.swap(index, j)
+				index++
+			}
+		}
+		index++
+	}
+	i.stratStep(numFolds)
+	return nil
+}
+
+//Help function needed for stratification of set
+func (i *Instances) stratStep(numFolds int) {
+	newVec := make([]Instance, 0, len(i.instances))
+	start := 0
+	for len(newVec) < len(i.instances) {
+		for j := start; j < len(i.instances); j += numFolds {
+			newVec = append(newVec, i.instances[j])
+		}
+		start++
+	}
+	i.instances = newVec
+}
+
+//Adds instances of one set to the end of another one. They are shared when
+//they already refer to the header of dest, so changing their values changes
+//them in both sets, otherwise they are copied
 func (i *Instances) copyInstances(from int, dest *Instances, num int) {
+	header := dest.Header()
 	for j := 0; j < num; j++ {
-		data := *i.Instance(from + j)
-		dest.instances = append(dest.instances, data)
+		instance := i.instances[from+j]
+		if instance.Header() != header {
+			instance = instance.Copy()
+			instance.SetHeader(header)
+		}
+		dest.instances = append(dest.instances, instance)
+		if hasMissingValue(i.instances[from+j]) {
+			dest.hasMissing = true
+		}
 	}
 }
 
 //Shuffles the instances in the set so that they are ordered randomly
 func (i *Instances) Randomize(seed int) {
-	rand.Seed(int64(seed))
+	rnd := rand.New(rand.NewSource(int64(seed)))
 	for j := range i.instances {
-		i.swap(j, rand.Intn(j+1))
+		i.swap(j, rnd.Intn(j+1))
 	}
 }
 
@@ -341,22 +446,116 @@ func (i *Instances) ClassIndex() int {
 	return i.classIndex
 }
 
+//Returns the summary of the header, HasMissing is kept up to date while
+//parsing and when the instances are set
+func (i *Instances) AttributesInfo() Attributes {
+	info := newAttributesWithOutputs(i.datasetName, i.attributes, i.OutputIndices())
+	info.SetHasMissing(i.hasMissing)
+	return info
+}
+
+//Returns the indexes of the output attributes in ascending order, the ones
+//set by SetOutputIndices or else the class attribute
+func (i *Instances) OutputIndices() []int {
+	if i.outputs != nil {
+		outputs := make([]int, len(i.outputs))
+		copy(outputs, i.outputs)
+		return outputs
+	}
+	if i.classIndex >= 0 {
+		return []int{i.classIndex}
+	}
+	return []int{}
+}
+
+//Returns the header of the dataset, the one its instances refer to
+func (i *Instances) Header() *Header {
+	if i.header == nil {
+		i.header = NewHeader(i.datasetName, i.attributes, i.classIndex)
+	}
+	return i.header
+}
+
+//Makes the instances refer to a new header, after changes to the attributes
+func (i *Instances) shareHeader() {
+	i.header = nil
+	if len(i.instances) == 0 {
+		return
+	}
+	header := i.Header()
+	for _, instance := range i.instances {
+		instance.SetHeader(header)
+	}
+}
+
+func (i *Instances) HasMissing() bool {
+	return i.hasMissing
+}
+
 //Sets methods
 
 func (i *Instances) SetDatasetName(name string) {
 	i.datasetName = name
+	i.shareHeader()
 }
 
 func (i *Instances) SetInstances(insts []Instance) {
 	i.instances = insts
+	i.csr = nil
+	i.hasMissing = false
+	for _, instance := range insts {
+		if hasMissingValue(instance) {
+			i.hasMissing = true
+			break
+		}
+	}
+	i.shareHeader()
 }
 
 func (i *Instances) SetAttributes(attrs []Attribute) {
 	i.attributes = attrs
+	i.shareHeader()
+}
+
+//Makes the attributes the outputs of a multi-target dataset and the rest
+//inputs, the class index is not changed. There must be at least two outputs
+//as a single one is the class attribute, nil makes the class attribute the
+//output again. The directions of the attributes are set accordingly
+func (i *Instances) SetOutputIndices(outputs []int) error {
+	if outputs == nil {
+		i.outputs = nil
+		return nil
+	}
+	if len(outputs) < 2 {
+		return fmt.Errorf("A multi-target dataset needs at least 2 outputs, %d given", len(outputs))
+	}
+	sorted := make([]int, len(outputs))
+	copy(sorted, outputs)
+	sort.Ints(sorted)
+	for j, idx := range sorted {
+		if idx < 0 || idx >= len(i.attributes) {
+			return fmt.Errorf("Output index %d out of range, there are %d attributes", idx, len(i.attributes))
+		}
+		if j > 0 && idx == sorted[j-1] {
+			return fmt.Errorf("Output index %d given twice", idx)
+		}
+	}
+	attrs := make([]Attribute, len(i.attributes))
+	copy(attrs, i.attributes)
+	for j := range attrs {
+		attrs[j].SetDirection(INPUT)
+	}
+	for _, idx := range sorted {
+		attrs[idx].SetDirection(OUTPUT)
+	}
+	i.outputs = sorted
+	i.SetAttributes(attrs)
+	return nil
 }
 
 func (i *Instances) SetClassIndex(classIndex int) {
 	i.classIndex = classIndex
+	i.shareHeader()
 }
 
 func (i *Instances) String() string {
@@ -0,0 +1,258 @@
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//Description of a dataset exchanged as JSON Lines, every record is a JSON
+//object with one field per attribute. Example:
+//
+//	{"relation": "reviews", "class": "label", "attributes": [
+//		{"name": "text", "type": "string"},
+//		{"name": "stars", "type": "numeric"},
+//		{"name": "date", "type": "date", "format": "yyyy-MM-dd"},
+//		{"name": "label", "type": "nominal", "values": ["pos", "neg"]}]}
+type JSONSchema struct {
+	Relation string `json:"relation"`
+	//Name of the class attribute, empty for none
+	Class      string          `json:"class,omitempty"`
+	Attributes []JSONAttribute `json:"attributes"`
+}
+
+//An attribute of a JSONSchema, the name is the name of the field in the
+//records and the type one of numeric, nominal, string or date
+type JSONAttribute struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	//Values of a nominal attribute, if not given they are collected from the
+	//records in order of appearance
+	Values []string `json:"values,omitempty"`
+	//Format of a date attribute, a java SimpleDateFormat pattern
+	Format string `json:"format,omitempty"`
+}
+
+//Returns the schema describing the header of the instances
+func NewJSONSchema(instances Instances) (JSONSchema, error) {
+	var schema JSONSchema
+	schema.Relation = instances.DatasetName()
+	if instances.ClassIndex() >= 0 {
+		schema.Class = instances.Attribute(instances.ClassIndex()).Name()
+	}
+	schema.Attributes = make([]JSONAttribute, 0, len(instances.Attributes()))
+	for _, attr := range instances.Attributes() {
+		jsonAttr := JSONAttribute{Name: attr.Name()}
+		switch attr.Type() {
+		case NUMERIC:
+			jsonAttr.Type = "numeric"
+		case NOMINAL:
+			jsonAttr.Type = "nominal"
+			jsonAttr.Values = attr.Values()
+		case STRING:
+			jsonAttr.Type = "string"
+		case DATE:
+			jsonAttr.Type = "date"
+			jsonAttr.Format = attr.DateFormat()
+		default:
+			return schema, fmt.Errorf("JSON: attribute '%s' of type %d is not supported", attr.Name(), attr.Type())
+		}
+		schema.Attributes = append(schema.Attributes, jsonAttr)
+	}
+	return schema, nil
+}
+
+//Reads a schema written by WriteJSONSchema
+func ReadJSONSchema(reader io.Reader) (JSONSchema, error) {
+	var schema JSONSchema
+	err := json.NewDecoder(reader).Decode(&schema)
+	return schema, err
+}
+
+//Writes the schema of the instances as an indented JSON object
+func WriteJSONSchema(writer io.Writer, instances Instances) error {
+	schema, err := NewJSONSchema(instances)
+	if err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(writer, "%s\n", out)
+	return err
+}
+
+//Builds the empty dataset described by the schema
+func (schema *JSONSchema) header() (Instances, error) {
+	insts := NewInstancesWithClassIndex(-1)
+	insts.SetDatasetName(schema.Relation)
+	attrs := make([]Attribute, len(schema.Attributes))
+	for i, jsonAttr := range schema.Attributes {
+		attr := NewAttribute()
+		attr.SetName(jsonAttr.Name)
+		attr.SetIndex(i)
+		attr.SetDirection(INPUT)
+		if jsonAttr.Name == schema.Class {
+			insts.classIndex = i
+			attr.SetDirection(OUTPUT)
+		}
+		switch strings.ToLower(jsonAttr.Type) {
+		case "numeric", "real", "integer":
+			attr.SetType(NUMERIC)
+		case "nominal":
+			attr.SetType(NOMINAL)
+			attr.SetHasFixedBounds(true)
+			for _, value := range jsonAttr.Values {
+				if _, present := attr.ValuesIndexes()[value]; present {
+					return insts, fmt.Errorf("JSON: duplicate nominal value '%s' for '%s'", value, jsonAttr.Name)
+				}
+				attr.AddStringValue(value)
+			}
+		case "string":
+			attr.SetType(STRING)
+		case "date":
+			attr.SetType(DATE)
+			format := jsonAttr.Format
+			if format == "" {
+				format = DEFAULT_DATE_FORMAT
+			}
+			if err := attr.SetDateFormat(format); err != nil {
+				return insts, err
+			}
+		default:
+			return insts, fmt.Errorf("JSON: unsupported type '%s' for '%s'", jsonAttr.Type, jsonAttr.Name)
+		}
+		attrs[i] = attr
+	}
+	if schema.Class != "" && insts.classIndex < 0 {
+		return insts, fmt.Errorf("JSON: class field '%s' is not an attribute of the schema", schema.Class)
+	}
+	insts.SetAttributes(attrs)
+	return insts, nil
+}
+
+//Reads a dataset in JSON Lines format, one JSON object per line, with the
+//attributes described by schema. Absent and null fields are missing values
+//and fields not in the schema are ignored
+func ReadJSONLines(reader io.Reader, schema JSONSchema) (Instances, error) {
+	insts, err := schema.header()
+	if err != nil {
+		return insts, err
+	}
+	//nominal attributes without values in the schema take them from the data
+	openNominal := make([]bool, len(schema.Attributes))
+	for i := range schema.Attributes {
+		openNominal[i] = insts.attributes[i].IsNominal() && len(schema.Attributes[i].Values) == 0
+	}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.UseNumber()
+		var record map[string]interface{}
+		if err := decoder.Decode(&record); err != nil {
+			return insts, newParseError(lineNum, "bad JSON record: %s", err.Error())
+		}
+		values := make([]float64, len(insts.attributes))
+		for i := range insts.attributes {
+			attr := &insts.attributes[i]
+			field, present := record[attr.Name()]
+			if !present || field == nil {
+				values[i] = MissingValue()
+				insts.hasMissing = true
+				continue
+			}
+			var val string
+			switch v := field.(type) {
+			case string:
+				val = v
+			case json.Number:
+				if !attr.IsNumeric() && !attr.IsNominal() {
+					return insts, newParseError(lineNum, "field '%s' must be a string", attr.Name())
+				}
+				val = v.String()
+			case bool:
+				if !attr.IsNominal() {
+					return insts, newParseError(lineNum, "field '%s' can not be a boolean", attr.Name())
+				}
+				val = strconv.FormatBool(v)
+			default:
+				return insts, newParseError(lineNum, "field '%s' must be a number or a string", attr.Name())
+			}
+			if openNominal[i] {
+				attr.AddStringValue(val)
+			}
+			value, err := insts.parseValue(attr, val, true)
+			if err != nil {
+				return insts, newParseError(lineNum, "%s", err.Error())
+			}
+			values[i] = value
+		}
+		insts.instances = append(insts.instances, NewDenseInstance(1.0, values))
+	}
+	insts.shareHeader()
+	return insts, scanner.Err()
+}
+
+//Writes the instances in JSON Lines format, one JSON object per instance with
+//the fields in the order of the attributes. Missing values are written as
+//null, dates as strings in the attribute's format. The header can be written
+//with WriteJSONSchema
+func WriteJSONLines(writer io.Writer, instances Instances) error {
+	attrs := instances.Attributes()
+	names := make([][]byte, len(attrs))
+	for i := range attrs {
+		if attrs[i].IsRelational() {
+			return fmt.Errorf("JSON: relational attribute '%s' is not supported", attrs[i].Name())
+		}
+		name, err := json.Marshal(attrs[i].Name())
+		if err != nil {
+			return err
+		}
+		names[i] = name
+	}
+	w := bufio.NewWriter(writer)
+	for _, instance := range instances.Instances() {
+		w.WriteByte('{')
+		for idx := range attrs {
+			if idx > 0 {
+				w.WriteByte(',')
+			}
+			w.Write(names[idx])
+			w.WriteByte(':')
+			value := instance.Value(idx)
+			var field interface{}
+			switch {
+			case math.IsNaN(value):
+				field = nil
+			case attrs[idx].IsNominal() || attrs[idx].IsString():
+				field = attrs[idx].Values()[int(value)]
+			case attrs[idx].IsDate():
+				field = attrs[idx].FormatDate(value)
+			default:
+				field = value
+			}
+			out, err := json.Marshal(field)
+			if err != nil {
+				return fmt.Errorf("JSON: bad value for '%s': %s", attrs[idx].Name(), err.Error())
+			}
+			w.Write(out)
+		}
+		if _, err := w.WriteString("}\n"); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
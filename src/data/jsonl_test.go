@@ -0,0 +1,134 @@
+package data
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestJSONLinesRoundTrip(t *testing.T) {
+	insts := readTestARFF(t, reviewsARFF)
+	var schemaBuf, lines bytes.Buffer
+	if err := WriteJSONSchema(&schemaBuf, insts); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteJSONLines(&lines, insts); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := ReadJSONSchema(&schemaBuf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadJSONLines(&lines, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.ClassIndex() != 3 {
+		t.Errorf("class index %d, want 3", read.ClassIndex())
+	}
+	//the weights are not written
+	options := NewArffOptions()
+	options.Weights = false
+	var got, want bytes.Buffer
+	if err := WriteARFF(&got, read, options); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteARFF(&want, insts, options); err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != want.String() {
+		t.Errorf("read dataset differs:\n%s\nwant:\n%s", got.String(), want.String())
+	}
+}
+
+//Nominal values missing from the schema are collected from the records
+func TestJSONLinesOpenNominal(t *testing.T) {
+	schema := JSONSchema{Relation: "r", Class: "label", Attributes: []JSONAttribute{
+		{Name: "x", Type: "numeric"},
+		{Name: "label", Type: "nominal"},
+	}}
+	text := `{"x": 1, "label": "b", "other": true}
+{"label": "a"}
+{"x": null, "label": "b"}
+`
+	insts, err := ReadJSONLines(strings.NewReader(text), schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	label := insts.Attribute(1)
+	if values := label.Values(); len(values) != 2 || values[0] != "b" || values[1] != "a" {
+		t.Errorf("label values %v, want [b a]", values)
+	}
+	if !insts.Instance(1).IsMissingValue(0) || !insts.Instance(2).IsMissingValue(0) {
+		t.Errorf("absent and null fields are not missing")
+	}
+}
+
+func TestWriteJSONLines(t *testing.T) {
+	insts := readTestARFF(t, reviewsARFF)
+	var buf bytes.Buffer
+	if err := WriteJSONLines(&buf, insts); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"text":"great, really","stars":4.5,"posted":"2024-03-01 10:00:00","label":"pos"}
+{"text":"it's awful","stars":1,"posted":"2024-03-02 11:30:00","label":"neg"}
+{"text":null,"stars":0,"posted":null,"label":null}
+{"text":"\"quoted\" word","stars":3,"posted":"2024-03-05 08:30:00","label":"pos"}
+`
+	if buf.String() != want {
+		t.Errorf("WriteJSONLines wrote:\n%s\nwant:\n%s", buf.String(), want)
+	}
+	schema, err := NewJSONSchema(insts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema.Relation != "reviews" || schema.Class != "label" || len(schema.Attributes) != 4 ||
+		schema.Attributes[2].Format != "yyyy-MM-dd HH:mm:ss" || len(schema.Attributes[3].Values) != 2 {
+		t.Errorf("schema %+v", schema)
+	}
+}
+
+//Numbers and booleans are read as the values of nominal attributes
+func TestJSONLinesNominalNumbers(t *testing.T) {
+	schema := JSONSchema{Class: "ok", Attributes: []JSONAttribute{
+		{Name: "rating", Type: "nominal", Values: []string{"1", "2", "3"}},
+		{Name: "ok", Type: "nominal", Values: []string{"false", "true"}},
+	}}
+	insts, err := ReadJSONLines(strings.NewReader("{\"rating\": 3, \"ok\": true}\n\n{\"rating\": \"1\", \"ok\": false}\n"), schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(insts.Instances()) != 2 || insts.Instance(0).Value(0) != 2 || insts.Instance(0).Value(1) != 1 || insts.Instance(1).Value(0) != 0 {
+		t.Errorf("read %d instances: %v", len(insts.Instances()), insts.Instances())
+	}
+}
+
+func TestJSONLinesErrors(t *testing.T) {
+	schema := JSONSchema{Attributes: []JSONAttribute{
+		{Name: "n", Type: "numeric"},
+		{Name: "s", Type: "string"},
+		{Name: "c", Type: "nominal", Values: []string{"a", "b"}},
+	}}
+	for _, line := range []string{
+		`{"n": "many"}`,
+		`{"s": 5}`,
+		`{"n": true}`,
+		`{"c": "z"}`,
+		`{"n": [1]}`,
+		`{"n": 1`,
+	} {
+		if _, err := ReadJSONLines(strings.NewReader(line+"\n"), schema); err == nil {
+			t.Errorf("ReadJSONLines read %s", line)
+		}
+	}
+	for _, bad := range []JSONSchema{
+		{Attributes: []JSONAttribute{{Name: "c", Type: "nominal", Values: []string{"a", "a"}}}},
+		{Attributes: []JSONAttribute{{Name: "x", Type: "complex"}}},
+		{Class: "y", Attributes: []JSONAttribute{{Name: "x", Type: "numeric"}}},
+		{Attributes: []JSONAttribute{{Name: "d", Type: "date", Format: "'Day 1' yyyy"}}},
+	} {
+		if _, err := ReadJSONLines(strings.NewReader(""), bad); err == nil {
+			t.Errorf("ReadJSONLines accepted the schema %+v", bad)
+		}
+	}
+}
@@ -0,0 +1,404 @@
+package data
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+//Inserts an attribute at the given position, the instances get a missing
+//value for it. The attribute's indexes are updated and the class index moves
+//with the class attribute
+func (i *Instances) InsertAttributeAt(attr Attribute, position int) error {
+	if position < 0 || position > len(i.attributes) {
+		return fmt.Errorf("Attribute position %d out of range, there are %d attributes", position, len(i.attributes))
+	}
+	for j := range i.attributes {
+		if i.attributes[j].Name() == attr.Name() {
+			return fmt.Errorf("Attribute '%s' already exists", attr.Name())
+		}
+	}
+	attrs := make([]Attribute, 0, len(i.attributes)+1)
+	attrs = append(attrs, i.attributes[:position]...)
+	attrs = append(attrs, attr.Copy())
+	attrs = append(attrs, i.attributes[position:]...)
+	for j := range attrs {
+		attrs[j].SetIndex(j)
+	}
+	if i.classIndex >= position {
+		i.classIndex++
+	}
+	i.outputs = shiftOutputs(i.outputs, position, 1)
+	//the slice of instances may be shared with other datasets
+	insts := make([]Instance, len(i.instances))
+	for j, instance := range i.instances {
+		insts[j] = insertValueAt(instance, position)
+	}
+	i.attributes = attrs
+	i.SetInstances(insts)
+	return nil
+}
+
+//Deletes the attribute at the given position and its values, the class
+//attribute and the outputs can not be deleted
+func (i *Instances) DeleteAttributeAt(position int) error {
+	if position < 0 || position >= len(i.attributes) {
+		return fmt.Errorf("Attribute position %d out of range, there are %d attributes", position, len(i.attributes))
+	}
+	if position == i.classIndex {
+		return fmt.Errorf("Can't delete class attribute")
+	}
+	for _, idx := range i.outputs {
+		if idx == position {
+			return fmt.Errorf("Can't delete output attribute")
+		}
+	}
+	attrs := make([]Attribute, 0, len(i.attributes)-1)
+	attrs = append(attrs, i.attributes[:position]...)
+	attrs = append(attrs, i.attributes[position+1:]...)
+	for j := range attrs {
+		attrs[j].SetIndex(j)
+	}
+	if i.classIndex > position {
+		i.classIndex--
+	}
+	i.outputs = shiftOutputs(i.outputs, position+1, -1)
+	insts := make([]Instance, len(i.instances))
+	for j, instance := range i.instances {
+		insts[j] = deleteValueAt(instance, position)
+	}
+	i.attributes = attrs
+	i.SetInstances(insts)
+	return nil
+}
+
+//Returns the output indexes with those from position on moved by offset, nil
+//if there are no outputs
+func shiftOutputs(outputs []int, position, offset int) []int {
+	if outputs == nil {
+		return nil
+	}
+	shifted := make([]int, len(outputs))
+	for j, idx := range outputs {
+		shifted[j] = idx
+		if idx >= position {
+			shifted[j] += offset
+		}
+	}
+	return shifted
+}
+
+//Returns a new instance, of the same kind, with a missing value inserted at
+//the position
+func insertValueAt(instance Instance, position int) Instance {
+	values := make([]float64, 0, instance.NumValues()+1)
+	indices := make([]int, 0, instance.NumValues()+1)
+	inserted := false
+	for j := 0; j < instance.NumValues(); j++ {
+		idx := instance.Index(j)
+		if idx >= position {
+			if !inserted {
+				values = append(values, MissingValue())
+				indices = append(indices, position)
+				inserted = true
+			}
+			idx++
+		}
+		values = append(values, instance.ValueSparse(j))
+		indices = append(indices, idx)
+	}
+	if !inserted {
+		values = append(values, MissingValue())
+		indices = append(indices, position)
+	}
+	if instance.IsSparse() {
+		return NewSparseInstance(instance.Weight(), values, indices, instance.NumAttributes()+1)
+	}
+	return NewDenseInstance(instance.Weight(), values)
+}
+
+//Returns a new instance, of the same kind, without the value at the position
+func deleteValueAt(instance Instance, position int) Instance {
+	values := make([]float64, 0, instance.NumValues())
+	indices := make([]int, 0, instance.NumValues())
+	for j := 0; j < instance.NumValues(); j++ {
+		idx := instance.Index(j)
+		if idx == position {
+			continue
+		}
+		if idx > position {
+			idx--
+		}
+		values = append(values, instance.ValueSparse(j))
+		indices = append(indices, idx)
+	}
+	if instance.IsSparse() {
+		return NewSparseInstance(instance.Weight(), values, indices, instance.NumAttributes()-1)
+	}
+	return NewDenseInstance(instance.Weight(), values)
+}
+
+//Appends a copy of the instance, which must have as many attributes as the
+//dataset and, if it belongs to a dataset, a header compatible with this one
+func (i *Instances) Add(instance Instance) error {
+	if instance.NumAttributes() != len(i.attributes) {
+		return fmt.Errorf("Instance has %d attributes, the dataset has %d", instance.NumAttributes(), len(i.attributes))
+	}
+	header := i.Header()
+	if instance.Header() != nil {
+		if err := header.CheckCompatible(instance.Header()); err != nil {
+			return fmt.Errorf("Instance is not compatible with the dataset: %s", err.Error())
+		}
+	}
+	copied := instance.Copy()
+	copied.SetHeader(header)
+	i.instances = append(i.instances, copied)
+	i.csr = nil
+	if hasMissingValue(copied) {
+		i.hasMissing = true
+	}
+	return nil
+}
+
+//Appends copies of all the instances of another dataset, whose header must be
+//compatible with this one
+func (i *Instances) AddAll(other Instances) error {
+	if err := i.Header().CheckCompatible(other.Header()); err != nil {
+		return fmt.Errorf("Datasets are not compatible: %s", err.Error())
+	}
+	for _, instance := range other.instances {
+		if err := i.Add(instance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Deletes the instance at the given index
+func (i *Instances) Delete(index int) error {
+	if index < 0 || index >= len(i.instances) {
+		return fmt.Errorf("Instance index %d out of range, there are %d instances", index, len(i.instances))
+	}
+	insts := make([]Instance, 0, len(i.instances)-1)
+	insts = append(insts, i.instances[:index]...)
+	i.SetInstances(append(insts, i.instances[index+1:]...))
+	return nil
+}
+
+//Deletes the instances with a missing value for the attribute
+func (i *Instances) DeleteWithMissing(attIndex int) error {
+	if err := i.checkAttributeIndex(attIndex); err != nil {
+		return err
+	}
+	insts := make([]Instance, 0, len(i.instances))
+	for _, instance := range i.instances {
+		if !instance.IsMissingValue(attIndex) {
+			insts = append(insts, instance)
+		}
+	}
+	i.SetInstances(insts)
+	return nil
+}
+
+//Deletes the instances with a missing class value
+func (i *Instances) DeleteWithMissingClass() error {
+	if i.classIndex < 0 {
+		return ErrClassNotSet
+	}
+	return i.DeleteWithMissing(i.classIndex)
+}
+
+//Merges two datasets with the same number of instances column-wise, like
+//weka's mergeInstances. The instances take the weights of the first dataset
+//and are sparse if both are. The class and outputs are the ones of the first
+//dataset if it has any, else the ones of the second, the other attributes are
+//inputs
+func MergeInstances(first, second Instances) (Instances, error) {
+	merged := NewInstancesWithClassIndex(-1)
+	if len(first.instances) != len(second.instances) {
+		return merged, fmt.Errorf("Datasets have different numbers of instances: %d != %d", len(first.instances), len(second.instances))
+	}
+	names := make(map[string]bool, len(first.attributes))
+	attrs := make([]Attribute, 0, len(first.attributes)+len(second.attributes))
+	for _, attr := range append(first.Header().Attributes(), second.Header().Attributes()...) {
+		if names[attr.Name()] {
+			return merged, fmt.Errorf("Attribute '%s' is in both datasets", attr.Name())
+		}
+		names[attr.Name()] = true
+		attr.SetIndex(len(attrs))
+		attrs = append(attrs, attr)
+	}
+	offset := len(first.attributes)
+	if first.classIndex >= 0 || first.outputs != nil {
+		merged.classIndex = first.classIndex
+		merged.outputs = shiftOutputs(first.outputs, 0, 0)
+	} else if second.classIndex >= 0 || second.outputs != nil {
+		merged.classIndex = -1
+		if second.classIndex >= 0 {
+			merged.classIndex = offset + second.classIndex
+		}
+		merged.outputs = shiftOutputs(second.outputs, 0, offset)
+	}
+	for j := range attrs {
+		attrs[j].SetDirection(INPUT)
+	}
+	for _, idx := range merged.OutputIndices() {
+		attrs[idx].SetDirection(OUTPUT)
+	}
+	insts := make([]Instance, len(first.instances))
+	for j := range insts {
+		a, b := first.instances[j], second.instances[j]
+		values := make([]float64, 0, a.NumValues()+b.NumValues())
+		indices := make([]int, 0, a.NumValues()+b.NumValues())
+		for k := 0; k < a.NumValues(); k++ {
+			values = append(values, a.ValueSparse(k))
+			indices = append(indices, a.Index(k))
+		}
+		for k := 0; k < b.NumValues(); k++ {
+			values = append(values, b.ValueSparse(k))
+			indices = append(indices, offset+b.Index(k))
+		}
+		if a.IsSparse() && b.IsSparse() {
+			insts[j] = NewSparseInstance(a.Weight(), values, indices, len(attrs))
+		} else {
+			insts[j] = NewDenseInstanceFrom(NewSparseInstance(a.Weight(), values, indices, len(attrs)))
+		}
+	}
+	merged.datasetName = first.datasetName + "_" + second.datasetName
+	merged.attributes = attrs
+	merged.SetInstances(insts)
+	return merged, nil
+}
+
+//Returns a dataset with the same header and copies of the instances at the
+//given indexes, in that order. The copies refer to the subset's header, so
+//changes to the subset do not affect the instances of the dataset
+func (i *Instances) Subset(indices []int) (Instances, error) {
+	subset := NewInstancesWithInst(*i, len(indices))
+	subset.classIndex = i.classIndex
+	insts := make([]Instance, len(indices))
+	for j, idx := range indices {
+		if idx < 0 || idx >= len(i.instances) {
+			return subset, fmt.Errorf("Instance index %d out of range, there are %d instances", idx, len(i.instances))
+		}
+		insts[j] = i.instances[idx].Copy()
+	}
+	subset.SetInstances(insts)
+	return subset, nil
+}
+
+//Returns a dataset with the same header and copies of the instances for which
+//keep returns true, see Subset
+func (i *Instances) SubsetFunc(keep func(Instance) bool) Instances {
+	subset := NewInstancesWithInst(*i, 0)
+	subset.classIndex = i.classIndex
+	insts := make([]Instance, 0)
+	for _, instance := range i.instances {
+		if keep(instance) {
+			insts = append(insts, instance.Copy())
+		}
+	}
+	subset.SetInstances(insts)
+	return subset
+}
+
+//Sorts the instances by the values of the attribute, keeping the order of
+//equal ones. Nominal values are sorted by their declaration order, strings
+//alphabetically and missing values go last
+func (i *Instances) Sort(attIndex int) error {
+	if err := i.checkAttributeIndex(attIndex); err != nil {
+		return err
+	}
+	attr := &i.attributes[attIndex]
+	less := func(a, b float64) bool {
+		if math.IsNaN(b) {
+			return !math.IsNaN(a)
+		}
+		if math.IsNaN(a) {
+			return false
+		}
+		if attr.IsString() {
+			return attr.Values()[int(a)] < attr.Values()[int(b)]
+		}
+		return a < b
+	}
+	//the slice of instances may be shared with other datasets
+	insts := make([]Instance, len(i.instances))
+	copy(insts, i.instances)
+	sort.SliceStable(insts, func(j, k int) bool {
+		return less(insts[j].Value(attIndex), insts[k].Value(attIndex))
+	})
+	i.instances = insts
+	return nil
+}
+
+//Renames an attribute, the name must not be used by another one
+func (i *Instances) RenameAttribute(attIndex int, name string) error {
+	if err := i.checkAttributeIndex(attIndex); err != nil {
+		return err
+	}
+	for j := range i.attributes {
+		if j != attIndex && i.attributes[j].Name() == name {
+			return fmt.Errorf("Attribute '%s' already exists", name)
+		}
+	}
+	attrs := i.copyAttributes(attIndex)
+	attrs[attIndex].SetName(name)
+	i.SetAttributes(attrs)
+	return nil
+}
+
+//Sets the weight of an attribute
+func (i *Instances) SetAttributeWeight(attIndex int, weight float64) error {
+	if err := i.checkAttributeIndex(attIndex); err != nil {
+		return err
+	}
+	attrs := i.copyAttributes(attIndex)
+	attrs[attIndex].SetWeight(weight)
+	i.SetAttributes(attrs)
+	return nil
+}
+
+//Renames a value of a nominal or string attribute, the instances keep their
+//values as they refer to it by index
+func (i *Instances) RenameAttributeValue(attIndex int, value, newValue string) error {
+	if err := i.checkAttributeIndex(attIndex); err != nil {
+		return err
+	}
+	attr := &i.attributes[attIndex]
+	if !attr.IsNominal() && !attr.IsString() {
+		return fmt.Errorf("Attribute '%s' is neither nominal nor string", attr.Name())
+	}
+	index, present := attr.ValuesIndexes()[value]
+	if !present {
+		return fmt.Errorf("Value '%s' not found for '%s'", value, attr.Name())
+	}
+	if _, present := attr.ValuesIndexes()[newValue]; present {
+		return fmt.Errorf("Value '%s' already exists for '%s'", newValue, attr.Name())
+	}
+	//the attributes may be shared with other datasets, the renamed one is copied
+	attrs := i.copyAttributes(attIndex)
+	renamed := &attrs[attIndex]
+	renamed.Values()[index] = newValue
+	delete(renamed.ValuesIndexes(), value)
+	renamed.ValuesIndexes()[newValue] = index
+	i.SetAttributes(attrs)
+	return nil
+}
+
+//Returns an error if there is no attribute at the index
+func (i *Instances) checkAttributeIndex(attIndex int) error {
+	if attIndex < 0 || attIndex >= len(i.attributes) {
+		return fmt.Errorf("Attribute index %d out of range, there are %d attributes", attIndex, len(i.attributes))
+	}
+	return nil
+}
+
+//Returns a new slice with the attributes, the one at attIndex is a copy that
+//does not share its values
+func (i *Instances) copyAttributes(attIndex int) []Attribute {
+	attrs := make([]Attribute, len(i.attributes))
+	copy(attrs, i.attributes)
+	attrs[attIndex] = i.attributes[attIndex].Copy()
+	return attrs
+}
@@ -0,0 +1,158 @@
+package data
+
+import (
+	"testing"
+)
+
+//Changes to the header of a subset must not reach the rows of the dataset
+func TestSubsetDoesNotShareHeader(t *testing.T) {
+	insts := readTestARFF(t, weatherARFF)
+	subsets := make([]Instances, 0, 2)
+	sub, err := insts.Subset([]int{0, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	subsets = append(subsets, sub)
+	subsets = append(subsets, insts.SubsetFunc(func(inst Instance) bool { return inst.Value(0) == 0 }))
+	for _, sub := range subsets {
+		if err := sub.RenameAttribute(0, "renamed"); err != nil {
+			t.Fatal(err)
+		}
+		attr := insts.Instance(0).Header().Attribute(0)
+		if name := attr.Name(); name != "outlook" {
+			t.Errorf("row of the dataset has header attribute %q, want outlook", name)
+		}
+		if err := insts.Add(insts.Instance(0)); err != nil {
+			t.Errorf("Add: %v", err)
+		}
+	}
+	if got := sub.Instance(0).Value(0); got != 0 {
+		t.Errorf("subset value = %v, want 0", got)
+	}
+}
+
+func TestInsertAndDeleteAttribute(t *testing.T) {
+	insts := readTestARFF(t, weatherARFF)
+	attr := NewAttribute()
+	attr.SetName("id")
+	attr.SetType(NUMERIC)
+	if err := insts.InsertAttributeAt(attr, 0); err != nil {
+		t.Fatal(err)
+	}
+	if insts.ClassIndex() != 5 || !insts.Instance(0).IsMissingValue(0) || insts.Instance(0).Value(1) != 0 {
+		t.Errorf("after insert: class %d, row %v", insts.ClassIndex(), insts.Instance(0))
+	}
+	if err := insts.DeleteAttributeAt(5); err == nil {
+		t.Error("deleting the class attribute must fail")
+	}
+	if err := insts.DeleteAttributeAt(0); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := arffString(t, insts), arffString(t, readTestARFF(t, weatherARFF)); got != want {
+		t.Errorf("after delete:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCVSharesInstances(t *testing.T) {
+	insts := readTestARFF(t, weatherARFF)
+	test, err := insts.TestCV(7, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(test.Instances()) != 2 || test.Instance(0) != insts.Instance(0) {
+		t.Errorf("the test fold does not share the instances of the dataset")
+	}
+	if err := insts.RenameAttribute(0, "sky"); err != nil {
+		t.Fatal(err)
+	}
+	header := insts.Header()
+	if renamed := header.Attribute(0); renamed.Name() != "sky" {
+		t.Errorf("the header does not see the change of the attribute")
+	}
+	if insts.Instance(0).Header() != header {
+		t.Errorf("the instances do not refer to the new header")
+	}
+	//reading an attribute does not build the header again
+	if attr := insts.Attribute(1); attr.Name() != "temperature" || insts.Header() != header {
+		t.Errorf("Attribute changed the header")
+	}
+}
+
+//Changes to a copy of the dataset value must not reach the dataset, whose
+//slice of instances the copy shares
+func TestManipulationKeepsSource(t *testing.T) {
+	insts := readTestARFF(t, weatherARFF)
+	want := arffString(t, insts)
+	other := insts
+	attr := NewAttribute()
+	attr.SetName("id")
+	attr.SetType(NUMERIC)
+	if err := other.InsertAttributeAt(attr, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := arffString(t, insts); got != want {
+		t.Errorf("InsertAttributeAt changed the source dataset:\n%s", got)
+	}
+	if insts.Instance(0).NumAttributes() != 5 || insts.Instance(0).Value(1) != 85 {
+		t.Errorf("source row has %d attributes", insts.Instance(0).NumAttributes())
+	}
+	other = insts
+	if err := other.Sort(1); err != nil {
+		t.Fatal(err)
+	}
+	if got := arffString(t, insts); got != want {
+		t.Errorf("Sort changed the source dataset:\n%s", got)
+	}
+	if other.Instance(0).Value(1) != 0 {
+		t.Errorf("first sorted temperature %v, want 0", other.Instance(0).Value(1))
+	}
+}
+
+func TestManipulationIndexOutOfRange(t *testing.T) {
+	insts := readTestARFF(t, weatherARFF)
+	want := arffString(t, insts)
+	for _, index := range []int{-1, 14} {
+		if err := insts.Delete(index); err == nil {
+			t.Errorf("Delete(%d) did not fail", index)
+		}
+	}
+	for _, index := range []int{-1, 5} {
+		if err := insts.DeleteWithMissing(index); err == nil {
+			t.Errorf("DeleteWithMissing(%d) did not fail", index)
+		}
+		if err := insts.Sort(index); err == nil {
+			t.Errorf("Sort(%d) did not fail", index)
+		}
+		if err := insts.RenameAttribute(index, "x"); err == nil {
+			t.Errorf("RenameAttribute(%d) did not fail", index)
+		}
+		if err := insts.RenameAttributeValue(index, "sunny", "bright"); err == nil {
+			t.Errorf("RenameAttributeValue(%d) did not fail", index)
+		}
+	}
+	if got := arffString(t, insts); got != want {
+		t.Errorf("the dataset changed:\n%s", got)
+	}
+	if err := insts.Delete(13); err != nil || len(insts.Instances()) != 13 {
+		t.Errorf("Delete(13): %v, %d instances left", err, len(insts.Instances()))
+	}
+}
+
+func TestRandomizeSeed(t *testing.T) {
+	orders := make([]string, 0, 3)
+	for _, seed := range []int{1, 1, 2} {
+		insts := readTestARFF(t, weatherARFF)
+		insts.Randomize(seed)
+		train, err := insts.TrainCV(3, 1, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		orders = append(orders, arffString(t, insts)+arffString(t, train))
+	}
+	if orders[0] != orders[1] {
+		t.Errorf("shuffles with the same seed differ")
+	}
+	if orders[0] == orders[2] {
+		t.Errorf("shuffles with different seeds are the same")
+	}
+}
@@ -0,0 +1,181 @@
+package data
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+//Aligns datasets to a training header by attribute name, like weka's
+//InputMappedClassifier. Attributes of the header absent from the data or with
+//another type get missing values, extra attributes of the data are ignored,
+//values are reordered and nominal values unknown to the header become
+//missing. Every difference found is reported by Mismatches
+type HeaderMapper struct {
+	header *Header
+	source *Header
+	//Attributes of the mapped data, copies of the header's ones that receive
+	//the values of string and relational attributes
+	attributes []Attribute
+	//Index in the source of each attribute of the header, -1 if it is absent
+	attributeMap []int
+	//For nominal attributes, index in the header of each source value, -1 if
+	//the header does not have it
+	valueMap [][]int
+	mismatches []string
+}
+
+//Creates the mapping from data with the source header to the training header
+func NewHeaderMapper(header, source *Header) HeaderMapper {
+	var m HeaderMapper
+	m.header = header
+	m.source = source
+	m.attributes = header.Attributes()
+	m.attributeMap = make([]int, header.NumAttributes())
+	m.valueMap = make([][]int, header.NumAttributes())
+	used := make([]bool, source.NumAttributes())
+	for i := range m.attributes {
+		attr := &m.attributes[i]
+		m.attributeMap[i] = -1
+		j := source.AttributeIndex(attr.Name())
+		if j < 0 {
+			m.addMismatch("Attribute '%s' is not in the data, its values are missing", attr.Name())
+			continue
+		}
+		used[j] = true
+		sourceAttr := &source.attributes[j]
+		if sourceAttr.Type() != attr.Type() {
+			m.addMismatch("Attribute '%s' is %s in the data and %s in the header, its values are missing", attr.Name(), typeName(sourceAttr), typeName(attr))
+			continue
+		}
+		if attr.IsRelational() && attr.Relation() != nil && sourceAttr.Relation() != nil {
+			if err := NewHeader("", attr.Relation().Attributes(), attr.Relation().ClassIndex()).CheckCompatible(
+				NewHeader("", sourceAttr.Relation().Attributes(), sourceAttr.Relation().ClassIndex())); err != nil {
+				m.addMismatch("Relational attribute '%s' has another header in the data, its values are missing: %s", attr.Name(), err.Error())
+				continue
+			}
+		}
+		if i != j {
+			m.addMismatch("Attribute '%s' is at position %d in the data and %d in the header", attr.Name(), j+1, i+1)
+		}
+		m.attributeMap[i] = j
+		if attr.IsNominal() {
+			m.valueMap[i] = make([]int, len(sourceAttr.Values()))
+			for k, value := range sourceAttr.Values() {
+				index, present := attr.ValuesIndexes()[value]
+				if !present {
+					m.addMismatch("Value '%s' of '%s' is not in the header, it is mapped to missing", value, attr.Name())
+					index = -1
+				}
+				m.valueMap[i][k] = index
+			}
+		}
+	}
+	for j, isUsed := range used {
+		if !isUsed {
+			m.addMismatch("Attribute '%s' of the data is not in the header, it is ignored", source.attributes[j].Name())
+		}
+	}
+	return m
+}
+
+func (m *HeaderMapper) addMismatch(format string, args ...interface{}) {
+	m.mismatches = append(m.mismatches, fmt.Sprintf(format, args...))
+}
+
+//Returns the instance, with the source header, mapped to the training header.
+//It is sparse if the instance is
+func (m *HeaderMapper) MapInstance(instance Instance) Instance {
+	//string and relational values are taken from the instance's own header,
+	//which has the ones added after the mapper was created
+	source := m.source
+	if instance.Header() != nil {
+		source = instance.Header()
+	}
+	values := make([]float64, len(m.attributes))
+	for i := range m.attributes {
+		j := m.attributeMap[i]
+		if j < 0 {
+			values[i] = MissingValue()
+			continue
+		}
+		value := instance.Value(j)
+		if math.IsNaN(value) {
+			values[i] = value
+			continue
+		}
+		attr := &m.attributes[i]
+		switch attr.Type() {
+		case NOMINAL:
+			if index := m.valueMap[i][int(value)]; index >= 0 {
+				values[i] = float64(index)
+			} else {
+				values[i] = MissingValue()
+			}
+		case STRING:
+			values[i] = float64(attr.AddStringValue(source.attributes[j].Values()[int(value)]))
+		case RELATIONAL:
+			values[i] = float64(attr.AddRelation(source.attributes[j].RelationValue(int(value))))
+		default:
+			values[i] = value
+		}
+	}
+	mapped := Instance(NewDenseInstance(instance.Weight(), values))
+	if instance.IsSparse() {
+		mapped = NewSparseInstanceFrom(mapped)
+	}
+	return mapped
+}
+
+//Returns the instances mapped to the training header, they must have the
+//source header
+func (m *HeaderMapper) MapInstances(source Instances) (Instances, error) {
+	mapped := NewInstancesWithClassIndex(m.header.ClassIndex())
+	mapped.SetDatasetName(m.header.RelationName())
+	if err := m.source.CheckCompatible(source.Header()); err != nil {
+		return mapped, fmt.Errorf("Data does not have the header of the mapper: %s", err.Error())
+	}
+	insts := make([]Instance, len(source.Instances()))
+	for i, instance := range source.Instances() {
+		insts[i] = m.MapInstance(instance)
+	}
+	//the mapper keeps adding string and relational values, so every dataset
+	//gets its own copy of the attributes
+	attrs := make([]Attribute, len(m.attributes))
+	for i := range m.attributes {
+		attrs[i] = m.attributes[i].Copy()
+	}
+	mapped.attributes = attrs
+	mapped.SetInstances(insts)
+	return mapped, nil
+}
+
+//Maps a dataset to the header of a training set, see HeaderMapper
+func MapToHeader(header *Header, data Instances) (Instances, HeaderMapper, error) {
+	m := NewHeaderMapper(header, data.Header())
+	mapped, err := m.MapInstances(data)
+	return mapped, m, err
+}
+
+//Returns one line per difference found between the headers
+func (m *HeaderMapper) Report() string {
+	if len(m.mismatches) == 0 {
+		return "The data matches the header\n"
+	}
+	return strings.Join(m.mismatches, "\n") + "\n"
+}
+
+//Gets methods
+
+func (m *HeaderMapper) Header() *Header {
+	return m.header
+}
+
+func (m *HeaderMapper) Source() *Header {
+	return m.source
+}
+
+//Returns the differences found between the headers
+func (m *HeaderMapper) Mismatches() []string {
+	return m.mismatches
+}
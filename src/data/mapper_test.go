@@ -0,0 +1,83 @@
+package data
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+//Test data for weather with the columns reordered, humidity missing, an extra
+//id column and an outlook value unknown to the training header
+const shuffledWeatherARFF = `@relation shuffled
+@attribute play {no,yes}
+@attribute id numeric
+@attribute windy {TRUE,FALSE}
+@attribute temperature numeric
+@attribute outlook {foggy,rainy,sunny}
+@data
+yes,1,FALSE,70,rainy
+no,2,TRUE,60,foggy
+?,3,FALSE,80,sunny
+`
+
+func TestMapToHeader(t *testing.T) {
+	train := readTestARFF(t, weatherARFF)
+	test := readTestARFF(t, shuffledWeatherARFF)
+	mapped, m, err := MapToHeader(train.Header(), test)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mapped.Attributes()) != 5 || mapped.ClassIndex() != 4 {
+		t.Fatalf("mapped %d attributes with class %d, want 5 with class 4", len(mapped.Attributes()), mapped.ClassIndex())
+	}
+	missing := MissingValue()
+	want := [][]float64{
+		//outlook, temperature, humidity, windy, play in the training order
+		{2, 70, missing, 1, 0},
+		{missing, 60, missing, 0, 1},
+		{0, 80, missing, 1, missing},
+	}
+	for i, values := range want {
+		inst := mapped.Instance(i)
+		for j, value := range values {
+			got := inst.Value(j)
+			if math.IsNaN(value) != math.IsNaN(got) || (!math.IsNaN(value) && got != value) {
+				t.Errorf("instance %d attribute %d = %v, want %v", i, j, got, value)
+			}
+		}
+	}
+	report := m.Report()
+	for _, expected := range []string{
+		"'humidity' is not in the data",
+		"'foggy' of 'outlook' is not in the header",
+		"'id' of the data is not in the header",
+		"'play' is at position 1 in the data and 5 in the header",
+	} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("report does not contain %q:\n%s", expected, report)
+		}
+	}
+}
+
+func TestMapInstancesCopiesAttributes(t *testing.T) {
+	train := readTestARFF(t, "@relation r\n@attribute text string\n@attribute label {pos,neg}\n@data\nfine,pos\n")
+	first := readTestARFF(t, "@relation r\n@attribute text string\n@attribute label {pos,neg}\n@data\ngood,pos\n")
+	second := readTestARFF(t, "@relation r\n@attribute text string\n@attribute label {pos,neg}\n@data\nbad,neg\nworse,neg\n")
+	m := NewHeaderMapper(train.Header(), first.Header())
+	mappedFirst, err := m.MapInstances(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	textFirst := mappedFirst.Attribute(0)
+	before := strings.Join(textFirst.Values(), ",")
+	if _, err := m.MapInstances(second); err != nil {
+		t.Fatal(err)
+	}
+	textFirst = mappedFirst.Attribute(0)
+	if after := strings.Join(textFirst.Values(), ","); after != before {
+		t.Errorf("mapping other data changed the string values from %q to %q", before, after)
+	}
+	if got := textFirst.Values()[int(mappedFirst.Instance(0).Value(0))]; got != "good" {
+		t.Errorf("mapped string value %q, want good", got)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !unix
+
+package data
+
+import (
+	"io"
+	"os"
+)
+
+//Reads the whole file, memory mapping is only implemented for unix systems
+func mapFile(file *os.File, size int) ([]byte, func() error, error) {
+	content := make([]byte, size)
+	if _, err := io.ReadFull(file, content); err != nil {
+		return nil, nil, err
+	}
+	return content, func() error { return nil }, nil
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package data
+
+import (
+	"os"
+	"syscall"
+)
+
+//Maps the file in memory, the pages are copied on write so changes to the
+//values are not written back to the file
+func mapFile(file *os.File, size int) ([]byte, func() error, error) {
+	mapped, err := syscall.Mmap(int(file.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_PRIVATE)
+	if err != nil {
+		return nil, nil, err
+	}
+	return mapped, func() error { return syscall.Munmap(mapped) }, nil
+}
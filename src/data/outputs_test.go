@@ -0,0 +1,74 @@
+package data
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOutputIndices(t *testing.T) {
+	insts := readTestARFF(t, weatherARFF)
+	if got := insts.OutputIndices(); !reflect.DeepEqual(got, []int{4}) {
+		t.Errorf("OutputIndices = %v, want the class", got)
+	}
+	if err := insts.SetOutputIndices([]int{4, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if got := insts.OutputIndices(); !reflect.DeepEqual(got, []int{3, 4}) {
+		t.Errorf("OutputIndices = %v, want [3 4]", got)
+	}
+	info := insts.AttributesInfo()
+	if len(info.InputAttrs()) != 3 || len(info.OutputAttrs()) != 2 {
+		t.Errorf("AttributesInfo has %d inputs and %d outputs", len(info.InputAttrs()), len(info.OutputAttrs()))
+	}
+	attr := NewAttribute()
+	attr.SetName("id")
+	attr.SetType(NUMERIC)
+	if err := insts.InsertAttributeAt(attr, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := insts.OutputIndices(); !reflect.DeepEqual(got, []int{4, 5}) {
+		t.Errorf("OutputIndices after insert = %v, want [4 5]", got)
+	}
+	if err := insts.DeleteAttributeAt(4); err == nil {
+		t.Error("deleting an output must fail")
+	}
+	for _, bad := range [][]int{{1}, {1, 1}, {1, 9}} {
+		if err := insts.SetOutputIndices(bad); err == nil {
+			t.Errorf("SetOutputIndices(%v) must fail", bad)
+		}
+	}
+	if err := insts.SetOutputIndices(nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := insts.OutputIndices(); !reflect.DeepEqual(got, []int{5}) {
+		t.Errorf("OutputIndices after reset = %v, want the class", got)
+	}
+}
+
+//Merging two labelled datasets keeps one class, not two outputs
+func TestMergeKeepsSingleOutput(t *testing.T) {
+	first := readTestARFF(t, weatherARFF)
+	second := readTestARFF(t, strings.Replace(strings.Replace(weatherARFF, "play", "label", 1), "windy", "wind", 1))
+	for k := 0; k < 3; k++ {
+		if err := second.DeleteAttributeAt(0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	merged, err := MergeInstances(first, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := merged.OutputIndices(); !reflect.DeepEqual(got, []int{4}) {
+		t.Errorf("OutputIndices = %v, want [4]", got)
+	}
+	for j, attr := range merged.Attributes() {
+		want := INPUT
+		if j == 4 {
+			want = OUTPUT
+		}
+		if attr.Direction() != want {
+			t.Errorf("attribute %d has direction %d, want %d", j, attr.Direction(), want)
+		}
+	}
+}
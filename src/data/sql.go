@@ -0,0 +1,356 @@
+package data
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Options of the SQL loader
+type SQLOptions struct {
+	//Name of the dataset, QueryResult if empty
+	Relation string
+	//Name of the class column, empty for none. It is read as NOMINAL if it
+	//holds text
+	Class string
+	//Short text columns (CHAR, VARCHAR, ...) with at most this many distinct
+	//values are read as NOMINAL and as STRING otherwise, long text columns
+	//(TEXT, CLOB, ...) are always STRING
+	MaxNominalValues int
+	//Format of DATE attributes, a java SimpleDateFormat pattern, used for
+	//dates the driver returns as text
+	DateFormat string
+	//Types given by column name (NUMERIC, NOMINAL, STRING or DATE), they
+	//override the ones of the columns
+	Types map[string]int
+}
+
+//Returns the default options: no class, short text columns with at most 20
+//distinct values as NOMINAL and the ARFF date format
+func NewSQLOptions() SQLOptions {
+	var options SQLOptions
+	options.MaxNominalValues = 20
+	options.DateFormat = DEFAULT_DATE_FORMAT
+	options.Types = make(map[string]int)
+	return options
+}
+
+//Kinds of SQL columns, from their database type or their values
+const (
+	sqlUnknown = iota
+	sqlNumeric
+	sqlBoolean
+	sqlEnum
+	sqlDate
+	sqlShortText
+	sqlLongText
+)
+
+//Database type names of each kind, as returned by the drivers
+var sqlTypeKinds = map[string]int{
+	"INT": sqlNumeric, "INTEGER": sqlNumeric, "TINYINT": sqlNumeric, "SMALLINT": sqlNumeric,
+	"MEDIUMINT": sqlNumeric, "BIGINT": sqlNumeric, "INT2": sqlNumeric, "INT4": sqlNumeric,
+	"INT8": sqlNumeric, "SERIAL": sqlNumeric, "SMALLSERIAL": sqlNumeric, "BIGSERIAL": sqlNumeric,
+	"REAL": sqlNumeric, "FLOAT": sqlNumeric, "FLOAT4": sqlNumeric, "FLOAT8": sqlNumeric,
+	"DOUBLE": sqlNumeric, "DOUBLE PRECISION": sqlNumeric, "DECIMAL": sqlNumeric, "NUMERIC": sqlNumeric,
+	"NUMBER": sqlNumeric, "MONEY": sqlNumeric, "SMALLMONEY": sqlNumeric,
+	"BOOL": sqlBoolean, "BOOLEAN": sqlBoolean, "BIT": sqlBoolean,
+	"ENUM": sqlEnum, "SET": sqlEnum,
+	"DATE": sqlDate, "DATETIME": sqlDate, "DATETIME2": sqlDate, "SMALLDATETIME": sqlDate,
+	"DATETIMEOFFSET": sqlDate, "TIMESTAMP": sqlDate, "TIMESTAMPTZ": sqlDate,
+	"TIMESTAMP WITH TIME ZONE": sqlDate, "TIMESTAMP WITHOUT TIME ZONE": sqlDate,
+	"CHAR": sqlShortText, "VARCHAR": sqlShortText, "NCHAR": sqlShortText, "NVARCHAR": sqlShortText,
+	"VARCHAR2": sqlShortText, "NVARCHAR2": sqlShortText, "CHARACTER": sqlShortText,
+	"CHARACTER VARYING": sqlShortText, "BPCHAR": sqlShortText, "STRING": sqlShortText,
+	"TEXT": sqlLongText, "TINYTEXT": sqlLongText, "MEDIUMTEXT": sqlLongText, "LONGTEXT": sqlLongText,
+	"NTEXT": sqlLongText, "CLOB": sqlLongText, "NCLOB": sqlLongText,
+}
+
+//Runs the query with the arguments and reads its result, see ReadSQLRows
+func ReadSQL(db *sql.DB, query string, options SQLOptions, args ...interface{}) (Instances, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return NewInstancesWithClassIndex(-1), err
+	}
+	defer rows.Close()
+	return ReadSQLRows(rows, options)
+}
+
+//Reads the result of a query, like weka's DatabaseLoader, one attribute per
+//column. The type of an attribute comes from the database type of its column:
+//numbers are NUMERIC, dates and timestamps DATE, booleans NOMINAL with values
+//false and true, enums NOMINAL and text as described in SQLOptions. Columns
+//of types unknown to the loader are typed by their values. NULL values are
+//missing and nominal values are taken in order of appearance. The rows are
+//read before the types are decided, so they are all kept in memory
+func ReadSQLRows(rows *sql.Rows, options SQLOptions) (Instances, error) {
+	insts := NewInstancesWithClassIndex(-1)
+	insts.SetDatasetName(options.Relation)
+	if options.Relation == "" {
+		insts.SetDatasetName("QueryResult")
+	}
+	columns, err := rows.ColumnTypes()
+	if err != nil {
+		return insts, err
+	}
+	names := make([]string, len(columns))
+	for col, column := range columns {
+		if indexOf(names[:col], column.Name()) >= 0 {
+			return insts, fmt.Errorf("SQL: duplicate column '%s'", column.Name())
+		}
+		names[col] = column.Name()
+	}
+	for name := range options.Types {
+		if indexOf(names, name) < 0 {
+			return insts, fmt.Errorf("SQL: type given for unknown column '%s'", name)
+		}
+	}
+	classIndex := -1
+	if options.Class != "" {
+		if classIndex = indexOf(names, options.Class); classIndex < 0 {
+			return insts, fmt.Errorf("SQL: class column '%s' not found", options.Class)
+		}
+	}
+	//the values are scanned without conversion, so they are nil or one of
+	//the driver.Value types
+	table := make([][]interface{}, 0)
+	for rows.Next() {
+		row := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for col := range row {
+			dest[col] = &row[col]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return insts, err
+		}
+		table = append(table, row)
+	}
+	if err := rows.Err(); err != nil {
+		return insts, err
+	}
+	attrs := make([]Attribute, len(columns))
+	kinds := make([]int, len(columns))
+	for col, column := range columns {
+		kinds[col] = sqlColumnKind(column, table, col)
+		attr := NewAttribute()
+		attr.SetName(names[col])
+		attr.SetIndex(col)
+		attr.SetDirection(INPUT)
+		attr_type, present := options.Types[names[col]]
+		if !present {
+			attr_type = sqlAttributeType(kinds[col], table, col, options, col == classIndex)
+		}
+		attr.SetType(attr_type)
+		switch attr_type {
+		case NUMERIC, STRING:
+		case DATE:
+			if err := attr.SetDateFormat(options.DateFormat); err != nil {
+				return insts, err
+			}
+		case NOMINAL:
+			if kinds[col] == sqlBoolean {
+				attr.AddStringValue("false")
+				attr.AddStringValue("true")
+			}
+			for r, row := range table {
+				if row[col] == nil {
+					continue
+				}
+				text, err := sqlText(row[col], kinds[col])
+				if err != nil {
+					return insts, fmt.Errorf("SQL: row %d, column '%s': %s", r+1, names[col], err.Error())
+				}
+				attr.AddStringValue(text)
+			}
+			attr.SetHasFixedBounds(true)
+		default:
+			return insts, fmt.Errorf("SQL: unsupported type %d for column '%s'", attr_type, names[col])
+		}
+		attrs[col] = attr
+	}
+	if classIndex >= 0 {
+		attrs[classIndex].SetDirection(OUTPUT)
+	}
+	insts.classIndex = classIndex
+	insts.SetAttributes(attrs)
+	instances := make([]Instance, 0, len(table))
+	for r, row := range table {
+		values := make([]float64, len(attrs))
+		for col, value := range row {
+			if value == nil {
+				values[col] = MissingValue()
+				continue
+			}
+			var err error
+			if values[col], err = sqlValue(&attrs[col], value, kinds[col]); err != nil {
+				return insts, fmt.Errorf("SQL: row %d, column '%s': %s", r+1, names[col], err.Error())
+			}
+		}
+		instances = append(instances, NewDenseInstance(1.0, values))
+	}
+	insts.SetInstances(instances)
+	return insts, nil
+}
+
+//Returns the kind of a column from its database type or, if the loader does
+//not know it, from the Go types of its values
+func sqlColumnKind(column *sql.ColumnType, table [][]interface{}, col int) int {
+	dbType := strings.ToUpper(strings.TrimSpace(column.DatabaseTypeName()))
+	if paren := strings.Index(dbType, "("); paren >= 0 {
+		dbType = strings.TrimSpace(dbType[:paren])
+	}
+	dbType = strings.TrimSpace(strings.TrimPrefix(strings.TrimSuffix(dbType, " UNSIGNED"), "UNSIGNED "))
+	if kind, present := sqlTypeKinds[dbType]; present {
+		return kind
+	}
+	kind := sqlUnknown
+	for _, row := range table {
+		valueKind := sqlShortText
+		switch row[col].(type) {
+		case nil:
+			continue
+		case int64, float64:
+			valueKind = sqlNumeric
+		case bool:
+			valueKind = sqlBoolean
+		case time.Time:
+			valueKind = sqlDate
+		}
+		if kind != sqlUnknown && kind != valueKind {
+			return sqlShortText
+		}
+		kind = valueKind
+	}
+	if kind == sqlUnknown {
+		return sqlShortText
+	}
+	return kind
+}
+
+//Returns the attribute type for a kind of column
+func sqlAttributeType(kind int, table [][]interface{}, col int, options SQLOptions, isClass bool) int {
+	switch kind {
+	case sqlNumeric:
+		return NUMERIC
+	case sqlDate:
+		return DATE
+	case sqlBoolean, sqlEnum:
+		return NOMINAL
+	case sqlLongText:
+		if isClass {
+			return NOMINAL
+		}
+		return STRING
+	}
+	if isClass {
+		return NOMINAL
+	}
+	distinct := make(map[string]bool)
+	for _, row := range table {
+		if row[col] == nil {
+			continue
+		}
+		distinct[sqlString(row[col])] = true
+		if len(distinct) > options.MaxNominalValues {
+			return STRING
+		}
+	}
+	return NOMINAL
+}
+
+//Returns the internal value of an SQL value for the attribute, string values
+//are added to the attribute
+func sqlValue(attr *Attribute, value interface{}, kind int) (float64, error) {
+	switch attr.Type() {
+	case NUMERIC:
+		switch v := value.(type) {
+		case int64:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		case bool:
+			if v {
+				return 1, nil
+			}
+			return 0, nil
+		case time.Time:
+			return float64(v.UnixMilli()), nil
+		}
+		text, _ := sqlText(value, kind)
+		number, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return 0, fmt.Errorf("bad numeric value '%s'", text)
+		}
+		return number, nil
+	case DATE:
+		switch v := value.(type) {
+		case time.Time:
+			return float64(v.UnixMilli()), nil
+		case string, []byte:
+			text, _ := sqlText(v, kind)
+			return attr.ParseDate(strings.TrimSpace(text))
+		}
+		return 0, fmt.Errorf("value %v of type %T is not a date", value, value)
+	case NOMINAL:
+		text, err := sqlText(value, kind)
+		if err != nil {
+			return 0, err
+		}
+		index, present := attr.ValuesIndexes()[text]
+		if !present {
+			return 0, fmt.Errorf("nominal value '%s' not declared", text)
+		}
+		return float64(index), nil
+	case STRING:
+		text, err := sqlText(value, kind)
+		if err != nil {
+			return 0, err
+		}
+		return float64(attr.AddStringValue(text)), nil
+	}
+	return 0, fmt.Errorf("unsupported type %d", attr.Type())
+}
+
+//Returns the text of an SQL value, booleans of boolean columns (which may be
+//given as numbers, bytes or text) are false or true
+func sqlText(value interface{}, kind int) (string, error) {
+	if kind == sqlBoolean {
+		switch v := value.(type) {
+		case bool:
+			return strconv.FormatBool(v), nil
+		case int64:
+			return strconv.FormatBool(v != 0), nil
+		case []byte:
+			//BIT(1) values are returned as a single byte
+			if len(v) == 1 && v[0] <= 1 {
+				return strconv.FormatBool(v[0] == 1), nil
+			}
+		}
+		text := strings.TrimSpace(sqlString(value))
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return "", fmt.Errorf("bad boolean value '%s'", text)
+		}
+		return strconv.FormatBool(b), nil
+	}
+	return sqlString(value), nil
+}
+
+//Returns the text of an SQL value as it is
+func sqlString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	return fmt.Sprint(value)
+}
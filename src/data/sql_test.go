@@ -0,0 +1,178 @@
+package data
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"math"
+	"testing"
+	"time"
+)
+
+//Driver whose queries are the names of the tables in fakeTables
+type fakeDriver struct{}
+
+type fakeTable struct {
+	columns []string
+	types   []string
+	rows    [][]driver.Value
+}
+
+var fakeTables = make(map[string]fakeTable)
+
+type fakeConn struct{}
+
+type fakeStmt struct {
+	query string
+}
+
+type fakeRows struct {
+	table fakeTable
+	next  int
+}
+
+func init() {
+	sql.Register("fakedata", fakeDriver{})
+}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return fakeConn{}, nil
+}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{query}, nil
+}
+
+func (fakeConn) Close() error {
+	return nil
+}
+
+func (fakeConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("transactions are not supported")
+}
+
+func (s fakeStmt) Close() error {
+	return nil
+}
+
+func (s fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, fmt.Errorf("exec is not supported")
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	table, present := fakeTables[s.query]
+	if !present {
+		return nil, fmt.Errorf("no table %s", s.query)
+	}
+	return &fakeRows{table: table}, nil
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.table.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.table.types[index]
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.table.rows) {
+		return io.EOF
+	}
+	copy(dest, r.table.rows[r.next])
+	r.next++
+	return nil
+}
+
+func readTestSQL(t *testing.T, table string, options SQLOptions) Instances {
+	t.Helper()
+	db, err := sql.Open("fakedata", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	insts, err := ReadSQL(db, table, options)
+	if err != nil {
+		t.Fatalf("ReadSQL(%s): %v", table, err)
+	}
+	return insts
+}
+
+func TestSQLColumnTypes(t *testing.T) {
+	posted := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	fakeTables["types"] = fakeTable{
+		columns: []string{"id", "stars", "verified", "posted", "lang", "review", "extra"},
+		types:   []string{"int unsigned", "DECIMAL(3,1)", "BIT(1)", "TIMESTAMP", "VARCHAR(5)", "TEXT", ""},
+		rows: [][]driver.Value{
+			{int64(1), []byte("4.5"), []byte{1}, posted, "en", "great", int64(3)},
+			{int64(2), 1.0, []byte{0}, posted.Add(time.Hour), "es", "awful", 2.5},
+			{int64(3), nil, nil, nil, nil, nil, nil},
+		},
+	}
+	insts := readTestSQL(t, "types", NewSQLOptions())
+	types := []int{NUMERIC, NUMERIC, NOMINAL, DATE, NOMINAL, STRING, NUMERIC}
+	for col, want := range types {
+		if attr := insts.Attribute(col); attr.Type() != want {
+			t.Errorf("column %s has type %d, want %d", attr.Name(), attr.Type(), want)
+		}
+	}
+	if verified := insts.Attribute(2); verified.Values()[0] != "false" || verified.Values()[1] != "true" {
+		t.Errorf("verified has values %v", verified.Values())
+	}
+	first, second := insts.Instance(0), insts.Instance(1)
+	if first.Value(1) != 4.5 || first.Value(2) != 1 || second.Value(2) != 0 {
+		t.Errorf("first rows are %v and %v", first, second)
+	}
+	if first.Value(3) != float64(posted.UnixMilli()) {
+		t.Errorf("posted = %v, want %v", first.Value(3), posted.UnixMilli())
+	}
+	for col := 1; col < len(types); col++ {
+		if !math.IsNaN(insts.Instance(2).Value(col)) {
+			t.Errorf("NULL in column %d is not missing", col)
+		}
+	}
+	if insts.ClassIndex() != -1 || !insts.HasMissing() {
+		t.Errorf("class index %d, missing values %v", insts.ClassIndex(), insts.HasMissing())
+	}
+}
+
+func TestSQLMaxNominalValues(t *testing.T) {
+	fakeTables["colors"] = fakeTable{
+		columns: []string{"color", "label"},
+		types:   []string{"VARCHAR", "TEXT"},
+		rows: [][]driver.Value{
+			{"red", "yes"}, {"green", "no"}, {"blue", "yes"}, {"red", nil},
+		},
+	}
+	options := NewSQLOptions()
+	options.MaxNominalValues = 3
+	insts := readTestSQL(t, "colors", options)
+	if color := insts.Attribute(0); color.Type() != NOMINAL || len(color.Values()) != 3 {
+		t.Errorf("color has type %d and values %v, want 3 nominal values", color.Type(), color.Values())
+	}
+	if label := insts.Attribute(1); label.Type() != STRING {
+		t.Errorf("long text label has type %d, want STRING", label.Type())
+	}
+	options.MaxNominalValues = 2
+	options.Class = "label"
+	insts = readTestSQL(t, "colors", options)
+	if color := insts.Attribute(0); color.Type() != STRING {
+		t.Errorf("color has type %d, want STRING", color.Type())
+	}
+	label := insts.Attribute(1)
+	if label.Type() != NOMINAL || label.Direction() != OUTPUT || insts.ClassIndex() != 1 {
+		t.Errorf("class label has type %d and direction %d, class index %d", label.Type(), label.Direction(), insts.ClassIndex())
+	}
+	if !insts.Instance(3).ClassMissing(1) {
+		t.Errorf("NULL class is not missing")
+	}
+}
@@ -0,0 +1,223 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+//Summary statistics of an attribute, like weka's AttributeStats. Counts are
+//numbers of instances, the numeric statistics and the value weights take the
+//instances' weights into account
+type AttributeStats struct {
+	Name string `json:"name"`
+	//One of nominal, numeric, string, date or relational
+	Type  string `json:"type"`
+	Total int    `json:"total"`
+	//Instances with a missing value
+	Missing int `json:"missing"`
+	//Number of different values, and of values that appear only once
+	Distinct int `json:"distinct"`
+	Unique   int `json:"unique"`
+	//Numeric values that are integers and that are not
+	IntCount  int `json:"int_count"`
+	RealCount int `json:"real_count"`
+	//Statistics of numeric and date attributes, nil if they have no values
+	Numeric *NumericStats `json:"numeric,omitempty"`
+	//Counts of each value of a nominal attribute, in declaration order
+	Values []ValueCount `json:"values,omitempty"`
+}
+
+//Statistics of the values of a numeric attribute, like weka's Stats. StdDev is
+//0 if the sum of weights is not greater than 1
+type NumericStats struct {
+	Count  float64 `json:"count"`
+	Sum    float64 `json:"sum"`
+	SumSq  float64 `json:"sum_sq"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"std_dev"`
+}
+
+//Number of instances and sum of their weights for a nominal value
+type ValueCount struct {
+	Value  string  `json:"value"`
+	Count  int     `json:"count"`
+	Weight float64 `json:"weight"`
+}
+
+//Statistics of a whole dataset
+type DatasetSummary struct {
+	Relation     string  `json:"relation"`
+	NumInstances int     `json:"num_instances"`
+	SumOfWeights float64 `json:"sum_of_weights"`
+	//Name of the class attribute, empty if there is none
+	Class string `json:"class,omitempty"`
+	//Counts of the class values if the class is nominal
+	ClassDistribution []ValueCount   `json:"class_distribution,omitempty"`
+	Attributes        []AttributeStats `json:"attributes"`
+}
+
+//Returns the statistics of the attribute's values
+func (i *Instances) AttributeStats(attIndex int) AttributeStats {
+	attr := &i.attributes[attIndex]
+	stats := AttributeStats{Name: attr.Name(), Type: typeName(attr), Total: len(i.instances)}
+	if attr.IsNominal() {
+		stats.Values = make([]ValueCount, len(attr.Values()))
+		for j, value := range attr.Values() {
+			stats.Values[j].Value = value
+		}
+	}
+	var numeric NumericStats
+	counts := make(map[float64]int)
+	for _, instance := range i.instances {
+		value := instance.Value(attIndex)
+		if math.IsNaN(value) {
+			stats.Missing++
+			continue
+		}
+		counts[value]++
+		if attr.IsNominal() {
+			stats.Values[int(value)].Count++
+			stats.Values[int(value)].Weight += instance.Weight()
+		} else if attr.IsNumeric() {
+			if value == math.Trunc(value) {
+				stats.IntCount++
+			} else {
+				stats.RealCount++
+			}
+			weight := instance.Weight()
+			if numeric.Count == 0 || value < numeric.Min {
+				numeric.Min = value
+			}
+			if numeric.Count == 0 || value > numeric.Max {
+				numeric.Max = value
+			}
+			numeric.Count += weight
+			numeric.Sum += value * weight
+			numeric.SumSq += value * value * weight
+		}
+	}
+	//the values of string and relational attributes are indexes of distinct
+	//values in the attribute, nominal and numeric ones are compared directly
+	stats.Distinct = len(counts)
+	for _, count := range counts {
+		if count == 1 {
+			stats.Unique++
+		}
+	}
+	if attr.IsNumeric() && numeric.Count > 0 {
+		numeric.Mean = numeric.Sum / numeric.Count
+		if numeric.Count > 1 {
+			variance := (numeric.SumSq - numeric.Sum*numeric.Sum/numeric.Count) / (numeric.Count - 1)
+			numeric.StdDev = math.Sqrt(math.Max(variance, 0))
+		}
+		stats.Numeric = &numeric
+	}
+	return stats
+}
+
+//Returns the statistics of the dataset and all its attributes
+func (i *Instances) Summary() DatasetSummary {
+	summary := DatasetSummary{Relation: i.datasetName, NumInstances: len(i.instances)}
+	for _, instance := range i.instances {
+		summary.SumOfWeights += instance.Weight()
+	}
+	summary.Attributes = make([]AttributeStats, len(i.attributes))
+	for j := range i.attributes {
+		summary.Attributes[j] = i.AttributeStats(j)
+	}
+	if i.classIndex >= 0 && i.classIndex < len(i.attributes) {
+		summary.Class = i.attributes[i.classIndex].Name()
+		summary.ClassDistribution = summary.Attributes[i.classIndex].Values
+	}
+	return summary
+}
+
+//Writes the summary as an indented JSON object
+func (s *DatasetSummary) WriteJSON(writer io.Writer) error {
+	out, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(writer, "%s\n", out)
+	return err
+}
+
+//Returns the summary as text, like weka's toSummaryString followed by the
+//statistics of each attribute
+func (s *DatasetSummary) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Relation Name:  %s\n", s.Relation)
+	fmt.Fprintf(&sb, "Num Instances:  %d\n", s.NumInstances)
+	fmt.Fprintf(&sb, "Sum of Weights: %s\n", formatStat(s.SumOfWeights))
+	fmt.Fprintf(&sb, "Num Attributes: %d\n\n", len(s.Attributes))
+	fmt.Fprintf(&sb, "     %-24s %-10s %4s %4s %4s %12s %12s %6s\n", "Name", "Type", "Nom", "Int", "Real", "Missing", "Unique", "Dist")
+	for j, stats := range s.Attributes {
+		nominal := 0
+		if stats.Type == "nominal" {
+			nominal = stats.Total - stats.Missing
+		}
+		fmt.Fprintf(&sb, "%4d %-24s %-10s %4s %4s %4s %5d / %4s %5d / %4s %6d\n", j+1, truncate(stats.Name, 24), stats.Type,
+			percent(nominal, stats.Total), percent(stats.IntCount, stats.Total), percent(stats.RealCount, stats.Total),
+			stats.Missing, percent(stats.Missing, stats.Total), stats.Unique, percent(stats.Unique, stats.Total), stats.Distinct)
+	}
+	for _, stats := range s.Attributes {
+		fmt.Fprintf(&sb, "\n%s (%s)\n", stats.Name, stats.Type)
+		if stats.Numeric != nil {
+			fmt.Fprintf(&sb, "  Minimum  %s\n", formatStat(stats.Numeric.Min))
+			fmt.Fprintf(&sb, "  Maximum  %s\n", formatStat(stats.Numeric.Max))
+			fmt.Fprintf(&sb, "  Mean     %s\n", formatStat(stats.Numeric.Mean))
+			fmt.Fprintf(&sb, "  StdDev   %s\n", formatStat(stats.Numeric.StdDev))
+		}
+		for _, value := range stats.Values {
+			fmt.Fprintf(&sb, "  %-20s %6d %10s\n", truncate(value.Value, 20), value.Count, formatStat(value.Weight))
+		}
+	}
+	if s.Class != "" && len(s.ClassDistribution) > 0 {
+		fmt.Fprintf(&sb, "\nClass distribution (%s)\n", s.Class)
+		for _, value := range s.ClassDistribution {
+			fmt.Fprintf(&sb, "  %-20s %6d %6s\n", truncate(value.Value, 20), value.Count, percent(value.Count, s.NumInstances))
+		}
+	}
+	return sb.String()
+}
+
+//Returns the name of the attribute's type as written in ARFF headers
+func typeName(attr *Attribute) string {
+	switch attr.Type() {
+	case NOMINAL:
+		return "nominal"
+	case STRING:
+		return "string"
+	case DATE:
+		return "date"
+	case RELATIONAL:
+		return "relational"
+	}
+	return "numeric"
+}
+
+//Returns count as a rounded percentage of total
+func percent(count, total int) string {
+	if total == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%d%%", int(math.Round(100*float64(count)/float64(total))))
+}
+
+//Formats a statistic with at most 3 decimal places
+func formatStat(value float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.3f", value), "0"), ".")
+}
+
+//Cuts s to at most n characters
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
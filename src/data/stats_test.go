@@ -0,0 +1,132 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAttributeStatsNominal(t *testing.T) {
+	insts := readTestARFF(t, weatherARFF)
+	outlook := insts.AttributeStats(0)
+	if outlook.Type != "nominal" || outlook.Total != 14 || outlook.Missing != 0 || outlook.Distinct != 3 || outlook.Unique != 0 {
+		t.Errorf("outlook stats %+v", outlook)
+	}
+	want := []ValueCount{{"sunny", 5, 5}, {"overcast", 4, 4}, {"rainy", 5, 5}}
+	if !reflect.DeepEqual(outlook.Values, want) {
+		t.Errorf("outlook values %v, want %v", outlook.Values, want)
+	}
+	if outlook.Numeric != nil {
+		t.Errorf("nominal attribute has numeric stats %+v", outlook.Numeric)
+	}
+	windy := insts.AttributeStats(3)
+	want = []ValueCount{{"TRUE", 6, 6}, {"FALSE", 7, 7}}
+	if windy.Missing != 1 || !reflect.DeepEqual(windy.Values, want) {
+		t.Errorf("windy has %d missing and values %v, want 1 and %v", windy.Missing, windy.Values, want)
+	}
+}
+
+func TestAttributeStatsNumeric(t *testing.T) {
+	insts := readTestARFF(t, weatherARFF)
+	temperature := insts.AttributeStats(1)
+	//75 appears twice
+	if temperature.Missing != 0 || temperature.Distinct != 13 || temperature.Unique != 12 || temperature.IntCount != 14 || temperature.RealCount != 0 {
+		t.Errorf("temperature stats %+v", temperature)
+	}
+	numeric := temperature.Numeric
+	if numeric == nil {
+		t.Fatal("temperature has no numeric stats")
+	}
+	if numeric.Count != 14 || numeric.Min != 0 || numeric.Max != 85 || math.Abs(numeric.Mean-68.428571) > 1e-6 || math.Abs(numeric.StdDev-20.757628) > 1e-6 {
+		t.Errorf("temperature numeric stats %+v", *numeric)
+	}
+	humidity := insts.AttributeStats(2)
+	if humidity.Missing != 1 || humidity.Numeric.Count != 13 {
+		t.Errorf("humidity has %d missing and count %v, want 1 and 13", humidity.Missing, humidity.Numeric.Count)
+	}
+}
+
+func TestAttributeStatsWeights(t *testing.T) {
+	insts := readTestARFF(t, reviewsARFF)
+	text := insts.AttributeStats(0)
+	if text.Type != "string" || text.Missing != 1 || text.Distinct != 3 || text.Unique != 3 || text.Numeric != nil {
+		t.Errorf("text stats %+v", text)
+	}
+	//the second instance has weight 2
+	stars := insts.AttributeStats(1)
+	if stars.IntCount != 3 || stars.RealCount != 1 {
+		t.Errorf("stars has %d integer and %d real values, want 3 and 1", stars.IntCount, stars.RealCount)
+	}
+	if stars.Numeric.Count != 5 || math.Abs(stars.Numeric.Mean-1.9) > 1e-9 || math.Abs(stars.Numeric.StdDev-1.816590) > 1e-6 {
+		t.Errorf("stars numeric stats %+v", *stars.Numeric)
+	}
+	posted := insts.AttributeStats(2)
+	if posted.Type != "date" || posted.Missing != 1 || posted.Numeric == nil {
+		t.Errorf("posted stats %+v", posted)
+	}
+	label := insts.AttributeStats(3)
+	want := []ValueCount{{"pos", 2, 2}, {"neg", 1, 2}}
+	if label.Missing != 1 || !reflect.DeepEqual(label.Values, want) {
+		t.Errorf("label has %d missing and values %v, want 1 and %v", label.Missing, label.Values, want)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	insts := readTestARFF(t, reviewsARFF)
+	summary := insts.Summary()
+	if summary.Relation != "reviews" || summary.NumInstances != 4 || summary.SumOfWeights != 5 || summary.Class != "label" {
+		t.Errorf("summary %+v", summary)
+	}
+	if len(summary.Attributes) != 4 || !reflect.DeepEqual(summary.ClassDistribution, summary.Attributes[3].Values) {
+		t.Errorf("summary attributes %v and class distribution %v", summary.Attributes, summary.ClassDistribution)
+	}
+	insts.SetClassIndex(-1)
+	if summary = insts.Summary(); summary.Class != "" || summary.ClassDistribution != nil {
+		t.Errorf("summary without class has class %q and distribution %v", summary.Class, summary.ClassDistribution)
+	}
+}
+
+func TestSummaryJSON(t *testing.T) {
+	insts := readTestARFF(t, reviewsARFF)
+	summary := insts.Summary()
+	var buf bytes.Buffer
+	if err := summary.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var read DatasetSummary
+	if err := json.Unmarshal(buf.Bytes(), &read); err != nil {
+		t.Fatalf("bad JSON: %v\n%s", err, buf.String())
+	}
+	if !reflect.DeepEqual(read, summary) {
+		t.Errorf("JSON read back as %+v, want %+v", read, summary)
+	}
+	for _, field := range []string{`"num_instances": 4`, `"sum_of_weights": 5`, `"missing": 1`, `"int_count": 3`} {
+		if !strings.Contains(buf.String(), field) {
+			t.Errorf("JSON does not contain %s:\n%s", field, buf.String())
+		}
+	}
+}
+
+func TestSummaryString(t *testing.T) {
+	insts := readTestARFF(t, weatherARFF)
+	summary := insts.Summary()
+	text := summary.String()
+	for _, line := range []string{
+		"Relation Name:  weather\n",
+		"Num Instances:  14\n",
+		"Num Attributes: 5\n",
+		"   1 outlook                  nominal    100%   0%   0%     0 /   0%     0 /   0%      3\n",
+		"   3 humidity                 numeric      0%  93%   0%     1 /   7%     7 /  50%     10\n",
+		"  Mean     68.429\n",
+		"  StdDev   20.758\n",
+		"  TRUE                      6          6\n",
+		"\nClass distribution (play)\n  yes                       9    64%\n  no                        5    36%\n",
+	} {
+		if !strings.Contains(text, line) {
+			t.Errorf("summary does not contain %q:\n%s", line, text)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package data
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//Options of the SVMlight/LIBSVM loader
+type SVMLightOptions struct {
+	//Type of the class attribute, NOMINAL or NUMERIC, -1 to make it nominal
+	//if all the labels are integers
+	ClassType int
+	//Minimum number of features, so that train and test files with different
+	//highest indexes get the same attributes, 0 to use the highest index
+	NumFeatures int
+	//Attribute names by feature index, the name of the class is given with
+	//index 0. Features without a name are called att<index>
+	Names map[int]string
+}
+
+func NewSVMLightOptions() SVMLightOptions {
+	var options SVMLightOptions
+	options.ClassType = -1
+	options.NumFeatures = 0
+	options.Names = make(map[int]string)
+	return options
+}
+
+//A data line: the label and the value of each feature, indexes start at 1
+type svmLightRow struct {
+	label   string
+	indices []int
+	values  []float64
+}
+
+//Reads a dataset in SVMlight/LIBSVM format, one instance per line:
+//
+//	<label> <index>:<value> <index>:<value> ... # comment
+//
+//The features become numeric attributes and the label the last attribute,
+//which is set as the class. The instances are sparse, qid:<n> tokens are
+//ignored
+func ReadSVMLight(reader io.Reader, options SVMLightOptions) (Instances, error) {
+	insts := NewInstancesWithClassIndex(-1)
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+	rows := make([]svmLightRow, 0)
+	numFeatures := options.NumFeatures
+	integerLabels := true
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		if comment := strings.Index(line, "#"); comment >= 0 {
+			line = line[:comment]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		var row svmLightRow
+		label, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil || math.IsNaN(label) {
+			return insts, newParseError(lineNum, "bad label '%s'", fields[0])
+		}
+		//so that +1 and 1 are the same class
+		row.label = strconv.FormatFloat(label, 'g', -1, 64)
+		integerLabels = integerLabels && label == math.Trunc(label)
+		last := 0
+		for _, field := range fields[1:] {
+			sep := strings.Index(field, ":")
+			if sep < 0 {
+				return insts, newParseError(lineNum, "feature '%s' must be 'index:value'", field)
+			}
+			if field[:sep] == "qid" {
+				continue
+			}
+			idx, err := strconv.Atoi(field[:sep])
+			if err != nil || idx < 1 {
+				return insts, newParseError(lineNum, "bad feature index '%s'", field[:sep])
+			}
+			if idx <= last {
+				return insts, newParseError(lineNum, "feature indexes must be in ascending order, found %d after %d", idx, last)
+			}
+			last = idx
+			value, err := strconv.ParseFloat(field[sep+1:], 64)
+			if err != nil {
+				return insts, newParseError(lineNum, "bad value '%s' for feature %d", field[sep+1:], idx)
+			}
+			row.indices = append(row.indices, idx)
+			row.values = append(row.values, value)
+		}
+		if last > numFeatures {
+			numFeatures = last
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return insts, err
+	}
+	classType := options.ClassType
+	if classType < 0 {
+		classType = NUMERIC
+		if integerLabels {
+			classType = NOMINAL
+		}
+	}
+	if classType != NOMINAL && classType != NUMERIC {
+		return insts, fmt.Errorf("SVMLight: the class must be NOMINAL or NUMERIC, not %d", classType)
+	}
+	attrs := make([]Attribute, numFeatures+1)
+	for i := 0; i < numFeatures; i++ {
+		attrs[i] = NewAttribute()
+		attrs[i].SetName(svmLightName(options.Names, i+1, "att"+strconv.Itoa(i+1)))
+		attrs[i].SetIndex(i)
+		attrs[i].SetType(NUMERIC)
+		attrs[i].SetDirection(INPUT)
+	}
+	class := NewAttribute()
+	class.SetName(svmLightName(options.Names, 0, "class"))
+	class.SetIndex(numFeatures)
+	class.SetType(classType)
+	class.SetDirection(OUTPUT)
+	if classType == NOMINAL {
+		//the labels sorted by their numeric value, e.g. {-1,1}
+		labels := make([]string, 0)
+		seen := make(map[string]bool)
+		for _, row := range rows {
+			if !seen[row.label] {
+				seen[row.label] = true
+				labels = append(labels, row.label)
+			}
+		}
+		sort.Slice(labels, func(i, j int) bool {
+			a, _ := strconv.ParseFloat(labels[i], 64)
+			b, _ := strconv.ParseFloat(labels[j], 64)
+			return a < b
+		})
+		for _, label := range labels {
+			class.AddStringValue(label)
+		}
+		class.SetHasFixedBounds(true)
+	}
+	attrs[numFeatures] = class
+	insts.SetAttributes(attrs)
+	insts.SetClassIndex(numFeatures)
+	instances := make([]Instance, len(rows))
+	for r, row := range rows {
+		indices := make([]int, len(row.indices)+1)
+		values := make([]float64, len(row.values)+1)
+		for i, idx := range row.indices {
+			indices[i] = idx - 1
+			values[i] = row.values[i]
+		}
+		indices[len(row.indices)] = numFeatures
+		if classType == NOMINAL {
+			values[len(row.values)] = float64(class.ValuesIndexes()[row.label])
+		} else {
+			values[len(row.values)], _ = strconv.ParseFloat(row.label, 64)
+		}
+		instances[r] = NewSparseInstance(1.0, values, indices, numFeatures+1)
+	}
+	insts.SetInstances(instances)
+	return insts, nil
+}
+
+//Reads a dataset from a SVMlight/LIBSVM file, see ReadSVMLight
+func LoadSVMLight(path string, options SVMLightOptions) (Instances, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return NewInstancesWithClassIndex(-1), err
+	}
+	defer file.Close()
+	insts, err := ReadSVMLight(file, options)
+	insts.SetDatasetName(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	return insts, err
+}
+
+func svmLightName(names map[int]string, idx int, defaultName string) string {
+	if name, present := names[idx]; present {
+		return name
+	}
+	return defaultName
+}
+
+//Writes the dataset in SVMlight/LIBSVM format, zeros are omitted so sparse
+//instances such as the output of StringToWordVector are written as they are
+//stored. The attributes other than the class are numbered from 1 in order
+//and must be numeric or nominal (written as the index of the value). A
+//nominal class is written as its value if all the values are numbers and as
+//the index of the value otherwise. Missing values can not be written
+func WriteSVMLight(writer io.Writer, instances Instances) error {
+	classIndex := instances.ClassIndex()
+	if classIndex < 0 {
+		return ErrClassNotSet
+	}
+	attrs := instances.Attributes()
+	for i := range attrs {
+		if !attrs[i].IsNominal() && attrs[i].Type() != NUMERIC {
+			return fmt.Errorf("SVMLight: attribute '%s' is not numeric or nominal", attrs[i].Name())
+		}
+	}
+	class := &attrs[classIndex]
+	numericLabels := true
+	for _, value := range class.Values() {
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			numericLabels = false
+		}
+	}
+	w := bufio.NewWriter(writer)
+	for n, instance := range instances.Instances() {
+		label := instance.Value(classIndex)
+		if math.IsNaN(label) {
+			return fmt.Errorf("SVMLight: instance %d has a missing class", n+1)
+		}
+		if class.IsNominal() && numericLabels {
+			w.WriteString(class.Values()[int(label)])
+		} else {
+			w.WriteString(strconv.FormatFloat(label, 'g', -1, 64))
+		}
+		for j := 0; j < instance.NumValues(); j++ {
+			idx, value := instance.Index(j), instance.ValueSparse(j)
+			if idx == classIndex || value == 0 {
+				continue
+			}
+			if math.IsNaN(value) {
+				return fmt.Errorf("SVMLight: instance %d has a missing value for '%s'", n+1, attrs[idx].Name())
+			}
+			w.WriteString(" " + strconv.Itoa(svmLightIndex(idx, classIndex)) + ":" + strconv.FormatFloat(value, 'g', -1, 64))
+		}
+		if _, err := w.WriteString("\n"); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+//Writes the attribute names sidecar of WriteSVMLight: one "index<TAB>name"
+//line per attribute, index 0 is the class
+func WriteSVMLightNames(writer io.Writer, instances Instances) error {
+	classIndex := instances.ClassIndex()
+	if classIndex < 0 {
+		return ErrClassNotSet
+	}
+	w := bufio.NewWriter(writer)
+	for idx, attr := range instances.Attributes() {
+		if strings.ContainsAny(attr.Name(), "\t\n\r") {
+			return fmt.Errorf("SVMLight: attribute name '%s' contains a tab or a line break", attr.Name())
+		}
+		number := 0
+		if idx != classIndex {
+			number = svmLightIndex(idx, classIndex)
+		}
+		fmt.Fprintf(w, "%d\t%s\n", number, attr.Name())
+	}
+	return w.Flush()
+}
+
+//Reads an attribute names sidecar written by WriteSVMLightNames, the result
+//can be used as SVMLightOptions.Names
+func ReadSVMLightNames(reader io.Reader) (map[int]string, error) {
+	names := make(map[int]string)
+	scanner := bufio.NewScanner(reader)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		sep := strings.Index(line, "\t")
+		if sep < 0 {
+			return names, newParseError(lineNum, "expected 'index<TAB>name'")
+		}
+		idx, err := strconv.Atoi(line[:sep])
+		if err != nil || idx < 0 {
+			return names, newParseError(lineNum, "bad index '%s'", line[:sep])
+		}
+		names[idx] = line[sep+1:]
+	}
+	return names, scanner.Err()
+}
+
+//Returns the feature number of the attribute, the class is skipped
+func svmLightIndex(idx, classIndex int) int {
+	if idx > classIndex {
+		return idx
+	}
+	return idx + 1
+}
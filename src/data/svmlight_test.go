@@ -0,0 +1,136 @@
+package data
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const svmLightText = `-1 1:0.5 3:2 # first
+1 qid:3 2:1.5 3:-1
+-1 1:4
+`
+
+func TestSVMLightRoundTrip(t *testing.T) {
+	insts, err := ReadSVMLight(strings.NewReader(svmLightText), NewSVMLightOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(insts.Attributes()) != 4 || insts.ClassIndex() != 3 || !insts.Instance(0).IsSparse() {
+		t.Fatalf("read %d attributes, class index %d", len(insts.Attributes()), insts.ClassIndex())
+	}
+	if class := insts.Attribute(3); !class.IsNominal() {
+		t.Errorf("integer labels are not read as a nominal class")
+	}
+	var buf, names bytes.Buffer
+	if err := WriteSVMLight(&buf, insts); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteSVMLightNames(&names, insts); err != nil {
+		t.Fatal(err)
+	}
+	if want := "-1 1:0.5 3:2\n1 2:1.5 3:-1\n-1 1:4\n"; buf.String() != want {
+		t.Errorf("WriteSVMLight wrote:\n%s\nwant:\n%s", buf.String(), want)
+	}
+	options := NewSVMLightOptions()
+	if options.Names, err = ReadSVMLightNames(&names); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadSVMLight(&buf, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := arffString(t, read), arffString(t, insts); got != want {
+		t.Errorf("read dataset differs:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+//Nominal values and a class with non-numeric values are written as the index
+//of the value, missing values can not be written
+func TestSVMLightWriteNominal(t *testing.T) {
+	insts := readTestARFF(t, weatherARFF)
+	var buf bytes.Buffer
+	if err := WriteSVMLight(&buf, insts); err == nil {
+		t.Errorf("missing values were written")
+	}
+	insts.Delete(9)
+	insts.Delete(5)
+	buf.Reset()
+	if err := WriteSVMLight(&buf, insts); err != nil {
+		t.Fatal(err)
+	}
+	if first := strings.SplitN(buf.String(), "\n", 2)[0]; first != "1 2:85 3:85 4:1" {
+		t.Errorf("first line is %q", first)
+	}
+}
+
+func TestSVMLightExplicitZerosAndLabels(t *testing.T) {
+	//+1 and 1 are the same class, 1:0 is an explicit zero
+	text := "+1 1:0 2:3\n1 2:1e-3\n-1 3:0\n"
+	options := NewSVMLightOptions()
+	options.NumFeatures = 5
+	options.Names = map[int]string{0: "spam", 2: "free"}
+	insts, err := ReadSVMLight(strings.NewReader(text), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(insts.Attributes()) != 6 || insts.ClassIndex() != 5 {
+		t.Fatalf("read %d attributes with class %d, want 6 with class 5", len(insts.Attributes()), insts.ClassIndex())
+	}
+	class, free, other := insts.Attribute(5), insts.Attribute(1), insts.Attribute(4)
+	if class.Name() != "spam" || free.Name() != "free" || other.Name() != "att5" {
+		t.Errorf("attribute names %q, %q, %q", class.Name(), free.Name(), other.Name())
+	}
+	if strings.Join(class.Values(), ",") != "-1,1" {
+		t.Errorf("class values %v, want [-1 1]", class.Values())
+	}
+	if first := insts.Instance(0); first.Value(0) != 0 || first.Value(1) != 3 || first.Value(5) != 1 {
+		t.Errorf("first instance %v", first)
+	}
+	var buf bytes.Buffer
+	if err := WriteSVMLight(&buf, insts); err != nil {
+		t.Fatal(err)
+	}
+	if want := "1 2:3\n1 2:0.001\n-1\n"; buf.String() != want {
+		t.Errorf("WriteSVMLight wrote:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestSVMLightNumericClass(t *testing.T) {
+	insts, err := ReadSVMLight(strings.NewReader("0.5 1:1\n2 1:2\n"), NewSVMLightOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if class := insts.Attribute(1); !class.IsNumeric() || insts.Instance(0).Value(1) != 0.5 {
+		t.Errorf("real labels read as type %d with value %v", class.Type(), insts.Instance(0).Value(1))
+	}
+	options := NewSVMLightOptions()
+	options.ClassType = NUMERIC
+	insts, err = ReadSVMLight(strings.NewReader("1 1:1\n2 1:2\n"), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if class := insts.Attribute(1); !class.IsNumeric() || insts.Instance(1).Value(1) != 2 {
+		t.Errorf("integer labels with a numeric class read as type %d", class.Type())
+	}
+}
+
+func TestSVMLightErrors(t *testing.T) {
+	for _, text := range []string{
+		"yes 1:1\n",
+		"1 1\n",
+		"1 0:1\n",
+		"1 3:1 2:1\n",
+		"1 2:1 2:3\n",
+		"1 1:abc\n",
+	} {
+		if _, err := ReadSVMLight(strings.NewReader(text), NewSVMLightOptions()); err == nil {
+			t.Errorf("ReadSVMLight read %q", text)
+		}
+	}
+	options := NewSVMLightOptions()
+	options.ClassType = STRING
+	if _, err := ReadSVMLight(strings.NewReader("1 1:1\n"), options); err == nil {
+		t.Errorf("ReadSVMLight read a string class")
+	}
+}
@@ -0,0 +1,236 @@
+package data
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+//Options of the text directory loader
+type TextDirectoryOptions struct {
+	//Character set of the files: UTF-8, UTF-16 (UTF-16LE, UTF-16BE),
+	//ISO-8859-1, windows-1252 or US-ASCII. If empty the files are read as
+	//UTF-8 unless they start with a UTF-16 byte order mark
+	Charset string
+	//Decodes the content of a file, it overrides Charset. Useful for other
+	//character sets
+	Decode func(content []byte) (string, error)
+	//Whether to add a STRING attribute with the path of each file, relative to
+	//the directory, to identify the documents
+	OutputFilename bool
+	//Name of the dataset, the name of the directory if empty
+	Relation string
+}
+
+//Returns the default options: UTF-8 files and no filename attribute
+func NewTextDirectoryOptions() TextDirectoryOptions {
+	var options TextDirectoryOptions
+	options.Charset = ""
+	options.OutputFilename = false
+	return options
+}
+
+//Reads a directory of documents, one per file, like weka's
+//TextDirectoryLoader. Each subdirectory is a class, named as it, and holds the
+//documents of that class, also those in nested directories. The dataset has a
+//STRING attribute text with the content of the files, a STRING attribute
+//filename if options.OutputFilename is set and a NOMINAL class attribute class
+//with the subdirectories' names sorted, the last one, so it is ready for
+//StringToWordVector. Files directly in the directory have a missing class,
+//to load unlabeled documents. Hidden files and directories (whose names start
+//with a dot) are skipped
+func ReadTextDirectory(dir string, options TextDirectoryOptions) (Instances, error) {
+	insts := NewInstancesWithClassIndex(-1)
+	decode := options.Decode
+	if decode == nil {
+		if _, err := decodeText(nil, options.Charset); err != nil {
+			return insts, err
+		}
+		decode = func(content []byte) (string, error) {
+			return decodeText(content, options.Charset)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return insts, err
+	}
+	classes := make([]string, 0)
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			classes = append(classes, entry.Name())
+		}
+	}
+	sort.Strings(classes)
+	text := NewAttribute()
+	text.SetName("text")
+	text.SetType(STRING)
+	attrs := []Attribute{text}
+	if options.OutputFilename {
+		filename := NewAttribute()
+		filename.SetName("filename")
+		filename.SetType(STRING)
+		attrs = append(attrs, filename)
+	}
+	class := NewAttribute()
+	class.SetName("class")
+	class.SetType(NOMINAL)
+	for _, name := range classes {
+		class.AddStringValue(name)
+	}
+	class.SetHasFixedBounds(true)
+	attrs = append(attrs, class)
+	for j := range attrs {
+		attrs[j].SetIndex(j)
+		attrs[j].SetDirection(INPUT)
+	}
+	classIndex := len(attrs) - 1
+	attrs[classIndex].SetDirection(OUTPUT)
+	instances := make([]Instance, 0)
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		relative, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		document, err := decode(content)
+		if err != nil {
+			return fmt.Errorf("%s: %s", path, err.Error())
+		}
+		values := make([]float64, len(attrs))
+		values[0] = float64(attrs[0].AddStringValue(document))
+		if options.OutputFilename {
+			values[1] = float64(attrs[1].AddStringValue(filepath.ToSlash(relative)))
+		}
+		values[classIndex] = MissingValue()
+		if parts := strings.Split(filepath.ToSlash(relative), "/"); len(parts) > 1 {
+			values[classIndex] = float64(attrs[classIndex].ValuesIndexes()[parts[0]])
+		}
+		instances = append(instances, NewDenseInstance(1.0, values))
+		return nil
+	})
+	if err != nil {
+		return insts, err
+	}
+	insts.SetDatasetName(options.Relation)
+	if options.Relation == "" {
+		if abs, err := filepath.Abs(dir); err == nil {
+			insts.SetDatasetName(filepath.Base(abs))
+		} else {
+			insts.SetDatasetName(filepath.Base(dir))
+		}
+	}
+	insts.classIndex = classIndex
+	insts.SetAttributes(attrs)
+	insts.SetInstances(instances)
+	return insts, nil
+}
+
+//Characters of windows-1252 for the bytes 0x80 to 0x9f, 0 for the bytes it
+//does not define, which are decoded as the same code point as in ISO-8859-1
+var windows1252 = [32]rune{
+	0x20ac, 0, 0x201a, 0x0192, 0x201e, 0x2026, 0x2020, 0x2021,
+	0x02c6, 0x2030, 0x0160, 0x2039, 0x0152, 0, 0x017d, 0,
+	0, 0x2018, 0x2019, 0x201c, 0x201d, 0x2022, 0x2013, 0x2014,
+	0x02dc, 0x2122, 0x0161, 0x203a, 0x0153, 0, 0x017e, 0x0178,
+}
+
+var (
+	utf8BOM    = []byte{0xef, 0xbb, 0xbf}
+	utf16LEBOM = []byte{0xff, 0xfe}
+	utf16BEBOM = []byte{0xfe, 0xff}
+)
+
+//Decodes text in the character set, byte order marks are removed and invalid
+//sequences replaced by U+FFFD
+func decodeText(content []byte, charset string) (string, error) {
+	switch strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(charset), "_", "-")) {
+	case "":
+		if bytes.HasPrefix(content, utf16LEBOM) || bytes.HasPrefix(content, utf16BEBOM) {
+			return decodeUTF16(content, false), nil
+		}
+		return decodeUTF8(content), nil
+	case "UTF-8", "UTF8":
+		return decodeUTF8(content), nil
+	case "UTF-16", "UTF16":
+		return decodeUTF16(content, false), nil
+	case "UTF-16LE", "UTF16LE":
+		return decodeUTF16(content, true), nil
+	case "UTF-16BE", "UTF16BE":
+		return decodeUTF16(content, false), nil
+	case "ISO-8859-1", "ISO8859-1", "LATIN1", "LATIN-1":
+		runes := make([]rune, len(content))
+		for j, b := range content {
+			runes[j] = rune(b)
+		}
+		return string(runes), nil
+	case "WINDOWS-1252", "CP1252":
+		runes := make([]rune, len(content))
+		for j, b := range content {
+			runes[j] = rune(b)
+			if b >= 0x80 && b <= 0x9f && windows1252[b-0x80] != 0 {
+				runes[j] = windows1252[b-0x80]
+			}
+		}
+		return string(runes), nil
+	case "US-ASCII", "ASCII":
+		runes := make([]rune, len(content))
+		for j, b := range content {
+			runes[j] = rune(b)
+			if b >= 0x80 {
+				runes[j] = utf8.RuneError
+			}
+		}
+		return string(runes), nil
+	}
+	return "", fmt.Errorf("Unsupported charset '%s'", charset)
+}
+
+func decodeUTF8(content []byte) string {
+	return strings.ToValidUTF8(string(bytes.TrimPrefix(content, utf8BOM)), string(utf8.RuneError))
+}
+
+//Decodes UTF-16 in the byte order of the byte order mark if there is one,
+//else in little or big endian order as given
+func decodeUTF16(content []byte, littleEndian bool) string {
+	if bytes.HasPrefix(content, utf16LEBOM) {
+		littleEndian = true
+		content = content[2:]
+	} else if bytes.HasPrefix(content, utf16BEBOM) {
+		littleEndian = false
+		content = content[2:]
+	}
+	units := make([]uint16, len(content)/2)
+	for j := range units {
+		if littleEndian {
+			units[j] = uint16(content[2*j]) | uint16(content[2*j+1])<<8
+		} else {
+			units[j] = uint16(content[2*j])<<8 | uint16(content[2*j+1])
+		}
+	}
+	text := string(utf16.Decode(units))
+	if len(content)%2 == 1 {
+		text += string(utf8.RuneError)
+	}
+	return text
+}
@@ -0,0 +1,138 @@
+package data
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+//Writes the files, given by their slash separated paths, under a temporary
+//directory and returns it
+func writeTextDirectory(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+//Returns the string value of the attribute in the instance
+func stringValue(insts Instances, i, attIndex int) string {
+	attr := insts.Attribute(attIndex)
+	return attr.Values()[int(insts.Instance(i).Value(attIndex))]
+}
+
+func TestReadTextDirectoryClasses(t *testing.T) {
+	dir := writeTextDirectory(t, map[string]string{
+		"neg/a.txt":        "bad",
+		"pos/b.txt":        "good",
+		"pos/nested/c.txt": "great",
+		"pos/.secret":      "hidden file",
+		".hidden/d.txt":    "hidden directory",
+		"unlabeled.txt":    "fine",
+	})
+	options := NewTextDirectoryOptions()
+	options.OutputFilename = true
+	options.Relation = "reviews"
+	insts, err := ReadTextDirectory(dir, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if insts.DatasetName() != "reviews" || len(insts.Attributes()) != 3 || insts.ClassIndex() != 2 {
+		t.Fatalf("read %q with %d attributes and class %d", insts.DatasetName(), len(insts.Attributes()), insts.ClassIndex())
+	}
+	class := insts.Attribute(2)
+	if class.Name() != "class" || !class.IsNominal() || !reflect.DeepEqual(class.Values(), []string{"neg", "pos"}) {
+		t.Errorf("class attribute %q with values %v", class.Name(), class.Values())
+	}
+	want := []struct {
+		text, filename, class string
+	}{
+		{"bad", "neg/a.txt", "neg"},
+		{"good", "pos/b.txt", "pos"},
+		{"great", "pos/nested/c.txt", "pos"},
+		{"fine", "unlabeled.txt", "?"},
+	}
+	if len(insts.Instances()) != len(want) {
+		t.Fatalf("read %d documents, want %d", len(insts.Instances()), len(want))
+	}
+	for i, w := range want {
+		label := "?"
+		if value := insts.Instance(i).Value(2); !math.IsNaN(value) {
+			label = class.Values()[int(value)]
+		}
+		if text, filename := stringValue(insts, i, 0), stringValue(insts, i, 1); text != w.text || filename != w.filename || label != w.class {
+			t.Errorf("document %d is %q in %q with class %s, want %q in %q with class %s", i, text, filename, label, w.text, w.filename, w.class)
+		}
+	}
+}
+
+func TestReadTextDirectoryNoClasses(t *testing.T) {
+	dir := writeTextDirectory(t, map[string]string{"a.txt": "one", "b.txt": "two"})
+	insts, err := ReadTextDirectory(dir, NewTextDirectoryOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if insts.DatasetName() != filepath.Base(dir) || len(insts.Attributes()) != 2 || len(insts.Instances()) != 2 {
+		t.Fatalf("read %q with %d attributes and %d documents", insts.DatasetName(), len(insts.Attributes()), len(insts.Instances()))
+	}
+	class := insts.Attribute(1)
+	if len(class.Values()) != 0 {
+		t.Errorf("class has values %v, want none", class.Values())
+	}
+	for i := range insts.Instances() {
+		if !insts.Instance(i).ClassMissing(insts.ClassIndex()) {
+			t.Errorf("document %d has a class", i)
+		}
+	}
+}
+
+func TestReadTextDirectoryCharsets(t *testing.T) {
+	tests := []struct {
+		charset, content, text string
+	}{
+		{"", "\xef\xbb\xbfcaf\xc3\xa9", "café"},
+		{"", "\xff\xfec\x00a\x00f\x00\xe9\x00", "café"},
+		{"UTF-16BE", "\x00c\x00a\x00f\x00\xe9", "café"},
+		{"ISO-8859-1", "caf\xe9", "café"},
+		{"windows-1252", "\x80 caf\xe9", "€ café"},
+		{"US-ASCII", "caf\xe9", "caf�"},
+		{"UTF-8", "caf\xe9", "caf�"},
+	}
+	for _, test := range tests {
+		dir := writeTextDirectory(t, map[string]string{"doc.txt": test.content})
+		options := NewTextDirectoryOptions()
+		options.Charset = test.charset
+		insts, err := ReadTextDirectory(dir, options)
+		if err != nil {
+			t.Errorf("charset %q: %v", test.charset, err)
+			continue
+		}
+		if text := stringValue(insts, 0, 0); text != test.text {
+			t.Errorf("charset %q decoded %q, want %q", test.charset, text, test.text)
+		}
+	}
+	options := NewTextDirectoryOptions()
+	options.Charset = "EBCDIC"
+	if _, err := ReadTextDirectory(t.TempDir(), options); err == nil || !strings.Contains(err.Error(), "EBCDIC") {
+		t.Errorf("unsupported charset gave error %v", err)
+	}
+	options = NewTextDirectoryOptions()
+	options.Decode = func(content []byte) (string, error) {
+		return strings.ToUpper(string(content)), nil
+	}
+	insts, err := ReadTextDirectory(writeTextDirectory(t, map[string]string{"doc.txt": "loud"}), options)
+	if err != nil || stringValue(insts, 0, 0) != "LOUD" {
+		t.Errorf("custom decoder gave %v", err)
+	}
+}
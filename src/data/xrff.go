@@ -0,0 +1,414 @@
+package data
+
+import (
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//Elements of weka's XRFF format, an XML version of ARFF that also stores
+//attribute weights:
+//
+//	<dataset name="weather">
+//	  <header>
+//	    <attributes>
+//	      <attribute name="outlook" type="nominal">
+//	        <labels><label>sunny</label><label>rainy</label></labels>
+//	        <metadata><property name="weight">2</property></metadata>
+//	      </attribute>
+//	      <attribute class="yes" name="play" type="nominal">...</attribute>
+//	    </attributes>
+//	  </header>
+//	  <body>
+//	    <instances>
+//	      <instance weight="0.5"><value>sunny</value><value>yes</value></instance>
+//	      <instance type="sparse"><value index="2">yes</value></instance>
+//	    </instances>
+//	  </body>
+//	</dataset>
+type xrffDataset struct {
+	XMLName    xml.Name        `xml:"dataset"`
+	Name       string          `xml:"name,attr"`
+	Attributes []xrffAttribute `xml:"header>attributes>attribute"`
+	Instances  []xrffInstance  `xml:"body>instances>instance"`
+}
+
+type xrffAttribute struct {
+	Name   string       `xml:"name,attr"`
+	Type   string       `xml:"type,attr"`
+	Class  string       `xml:"class,attr,omitempty"`
+	Format string       `xml:"format,attr,omitempty"`
+	Labels *xrffLabels `xml:"labels"`
+	//Inner attributes of a relational attribute
+	Attributes *xrffAttributes `xml:"attributes"`
+	Metadata   *xrffMetadata   `xml:"metadata"`
+}
+
+type xrffLabels struct {
+	Label []string `xml:"label"`
+}
+
+type xrffAttributes struct {
+	Attribute []xrffAttribute `xml:"attribute"`
+}
+
+type xrffMetadata struct {
+	Property []xrffProperty `xml:"property"`
+}
+
+type xrffProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xrffInstance struct {
+	Type   string      `xml:"type,attr,omitempty"`
+	Weight string      `xml:"weight,attr,omitempty"`
+	Values []xrffValue `xml:"value"`
+}
+
+type xrffValue struct {
+	//Index of the attribute in sparse instances, starting at 1
+	Index string `xml:"index,attr,omitempty"`
+	Text  string `xml:",chardata"`
+	//The bag of a relational value
+	Instances *xrffInstances `xml:"instances"`
+}
+
+type xrffInstances struct {
+	Instance []xrffInstance `xml:"instance"`
+}
+
+//Reads a dataset in weka's XRFF format. The attribute weights given as a
+//"weight" metadata property are set as the attributes' weights and the
+//class attribute, if any, is set as the class
+func ReadXRFF(reader io.Reader) (Instances, error) {
+	insts := NewInstancesWithClassIndex(-1)
+	var dataset xrffDataset
+	if err := xml.NewDecoder(reader).Decode(&dataset); err != nil {
+		return insts, fmt.Errorf("XRFF: %s", err.Error())
+	}
+	insts.SetDatasetName(dataset.Name)
+	attrs, classIndex, err := readXrffAttributes(dataset.Attributes)
+	if err != nil {
+		return insts, err
+	}
+	if len(attrs) == 0 {
+		return insts, fmt.Errorf("XRFF: no attributes declared")
+	}
+	insts.SetAttributes(attrs)
+	insts.SetClassIndex(classIndex)
+	instances := make([]Instance, len(dataset.Instances))
+	for i, x := range dataset.Instances {
+		instance, err := insts.xrffInstance(x)
+		if err != nil {
+			return insts, fmt.Errorf("XRFF: instance %d: %s", i+1, err.Error())
+		}
+		instances[i] = instance
+	}
+	insts.SetInstances(instances)
+	return insts, nil
+}
+
+//Reads a dataset from a XRFF file, compressed with gzip if its name ends
+//with .gz
+func LoadXRFF(path string) (Instances, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return NewInstancesWithClassIndex(-1), err
+	}
+	defer file.Close()
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return NewInstancesWithClassIndex(-1), err
+		}
+		defer gz.Close()
+		reader = gz
+	}
+	return ReadXRFF(reader)
+}
+
+//Builds the attributes of a header, the class index is -1 if none is marked
+func readXrffAttributes(xattrs []xrffAttribute) ([]Attribute, int, error) {
+	attrs := make([]Attribute, len(xattrs))
+	classIndex := -1
+	for i, x := range xattrs {
+		attr := NewAttribute()
+		attr.SetName(x.Name)
+		attr.SetIndex(i)
+		attr.SetDirection(INPUT)
+		if x.Class == "yes" {
+			if classIndex >= 0 {
+				return nil, -1, fmt.Errorf("XRFF: more than one class attribute, '%s' and '%s'", attrs[classIndex].Name(), x.Name)
+			}
+			classIndex = i
+			attr.SetDirection(OUTPUT)
+		}
+		switch strings.ToLower(x.Type) {
+		case "numeric", "real", "integer":
+			attr.SetType(NUMERIC)
+		case "nominal":
+			attr.SetType(NOMINAL)
+			attr.SetHasFixedBounds(true)
+			labels := make([]string, 0)
+			if x.Labels != nil {
+				labels = x.Labels.Label
+			}
+			for _, label := range labels {
+				if _, present := attr.ValuesIndexes()[label]; present {
+					return nil, -1, fmt.Errorf("XRFF: duplicate nominal value '%s' for '%s'", label, x.Name)
+				}
+				attr.AddStringValue(label)
+			}
+		case "string":
+			attr.SetType(STRING)
+		case "date":
+			attr.SetType(DATE)
+			format := x.Format
+			if format == "" {
+				format = DEFAULT_DATE_FORMAT
+			}
+			if err := attr.SetDateFormat(format); err != nil {
+				return nil, -1, err
+			}
+		case "relational":
+			attr.SetType(RELATIONAL)
+			if x.Attributes == nil {
+				return nil, -1, fmt.Errorf("XRFF: relational attribute '%s' has no attributes", x.Name)
+			}
+			inner, _, err := readXrffAttributes(x.Attributes.Attribute)
+			if err != nil {
+				return nil, -1, err
+			}
+			relation := NewInstancesWithClassIndex(-1)
+			relation.SetDatasetName(x.Name)
+			relation.SetAttributes(inner)
+			attr.SetRelation(&relation)
+		default:
+			return nil, -1, fmt.Errorf("XRFF: unsupported type '%s' for '%s'", x.Type, x.Name)
+		}
+		properties := make([]xrffProperty, 0)
+		if x.Metadata != nil {
+			properties = x.Metadata.Property
+		}
+		for _, property := range properties {
+			if property.Name != "weight" {
+				continue
+			}
+			weight, err := strconv.ParseFloat(strings.TrimSpace(property.Value), 64)
+			if err != nil {
+				return nil, -1, fmt.Errorf("XRFF: bad weight '%s' for '%s'", property.Value, x.Name)
+			}
+			attr.SetWeight(weight)
+		}
+		attrs[i] = attr
+	}
+	return attrs, classIndex, nil
+}
+
+//Builds an instance of inst from its XML element
+func (inst *Instances) xrffInstance(x xrffInstance) (Instance, error) {
+	weight := 1.0
+	if x.Weight != "" {
+		var err error
+		weight, err = strconv.ParseFloat(strings.TrimSpace(x.Weight), 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad instance weight '%s'", x.Weight)
+		}
+	}
+	sparse := x.Type == "sparse"
+	if !sparse && len(x.Values) != len(inst.attributes) {
+		return nil, fmt.Errorf("%d values found, there are %d attributes", len(x.Values), len(inst.attributes))
+	}
+	values := make([]float64, len(x.Values))
+	indices := make([]int, 0, len(x.Values))
+	last := -1
+	for i, value := range x.Values {
+		idx := i
+		if sparse {
+			index, err := strconv.Atoi(strings.TrimSpace(value.Index))
+			if err != nil || index < 1 || index > len(inst.attributes) {
+				return nil, fmt.Errorf("bad attribute index '%s'", value.Index)
+			}
+			idx = index - 1
+			if idx <= last {
+				return nil, fmt.Errorf("attribute indexes must be in ascending order, found %d after %d", idx+1, last+1)
+			}
+			last = idx
+			indices = append(indices, idx)
+		}
+		attr := &inst.attributes[idx]
+		text := value.Text
+		if !attr.IsString() {
+			text = strings.TrimSpace(text)
+		}
+		switch {
+		case strings.TrimSpace(text) == ARFF_MISSING:
+			values[i] = MissingValue()
+			inst.hasMissing = true
+		case attr.IsRelational():
+			rows := make([]xrffInstance, 0)
+			if value.Instances != nil {
+				rows = value.Instances.Instance
+			}
+			bag := NewInstancesWithInst(*attr.Relation(), len(rows))
+			for _, row := range rows {
+				bagInstance, err := bag.xrffInstance(row)
+				if err != nil {
+					return nil, fmt.Errorf("bad value for relational attribute '%s': %s", attr.Name(), err.Error())
+				}
+				bag.instances = append(bag.instances, bagInstance)
+			}
+			bag.shareHeader()
+			values[i] = float64(attr.AddRelation(bag))
+		default:
+			parsed, err := inst.parseValue(attr, text, true)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = parsed
+		}
+	}
+	if sparse {
+		return NewSparseInstance(weight, values, indices, len(inst.attributes)), nil
+	}
+	return NewDenseInstance(weight, values), nil
+}
+
+//Writes the dataset in weka's XRFF format, attribute weights other than 1
+//are written as "weight" metadata properties
+func WriteXRFF(writer io.Writer, instances Instances) error {
+	var dataset xrffDataset
+	dataset.Name = instances.DatasetName()
+	attrs, err := writeXrffAttributes(instances.Attributes(), instances.ClassIndex())
+	if err != nil {
+		return err
+	}
+	dataset.Attributes = attrs
+	dataset.Instances, err = writeXrffInstances(instances)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(dataset); err != nil {
+		return err
+	}
+	_, err = io.WriteString(writer, "\n")
+	return err
+}
+
+//Writes the dataset to a XRFF file, compressed with gzip if its name ends
+//with .gz
+func SaveXRFF(path string, instances Instances) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	var writer io.Writer = file
+	var gz *gzip.Writer
+	if strings.HasSuffix(path, ".gz") {
+		gz = gzip.NewWriter(file)
+		writer = gz
+	}
+	if err := WriteXRFF(writer, instances); err != nil {
+		file.Close()
+		return err
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	return file.Close()
+}
+
+func writeXrffAttributes(attrs []Attribute, classIndex int) ([]xrffAttribute, error) {
+	xattrs := make([]xrffAttribute, len(attrs))
+	for i := range attrs {
+		attr := &attrs[i]
+		x := xrffAttribute{Name: attr.Name()}
+		if i == classIndex {
+			x.Class = "yes"
+		}
+		switch attr.Type() {
+		case NUMERIC:
+			x.Type = "numeric"
+		case NOMINAL:
+			x.Type = "nominal"
+			x.Labels = &xrffLabels{Label: attr.Values()}
+		case STRING:
+			x.Type = "string"
+		case DATE:
+			x.Type = "date"
+			x.Format = attr.DateFormat()
+		case RELATIONAL:
+			x.Type = "relational"
+			inner, err := writeXrffAttributes(attr.Relation().Attributes(), -1)
+			if err != nil {
+				return nil, err
+			}
+			x.Attributes = &xrffAttributes{Attribute: inner}
+		default:
+			return nil, fmt.Errorf("XRFF: attribute '%s' of type %d is not supported", attr.Name(), attr.Type())
+		}
+		if attr.Weight() != 1 {
+			weight := xrffProperty{Name: "weight", Value: strconv.FormatFloat(attr.Weight(), 'g', -1, 64)}
+			x.Metadata = &xrffMetadata{Property: []xrffProperty{weight}}
+		}
+		xattrs[i] = x
+	}
+	return xattrs, nil
+}
+
+func writeXrffInstances(instances Instances) ([]xrffInstance, error) {
+	attrs := instances.Attributes()
+	xinsts := make([]xrffInstance, len(instances.Instances()))
+	for n, instance := range instances.Instances() {
+		var x xrffInstance
+		if instance.Weight() != 1 {
+			x.Weight = strconv.FormatFloat(instance.Weight(), 'g', -1, 64)
+		}
+		sparse := instance.IsSparse()
+		if sparse {
+			x.Type = "sparse"
+		}
+		x.Values = make([]xrffValue, instance.NumValues())
+		for i := range x.Values {
+			idx, value := instance.Index(i), instance.ValueSparse(i)
+			if sparse {
+				x.Values[i].Index = strconv.Itoa(idx + 1)
+			}
+			attr := &attrs[idx]
+			switch {
+			case math.IsNaN(value):
+				x.Values[i].Text = ARFF_MISSING
+			case attr.IsNominal() || attr.IsString():
+				x.Values[i].Text = attr.Values()[int(value)]
+			case attr.IsDate():
+				x.Values[i].Text = attr.FormatDate(value)
+			case attr.IsRelational():
+				bag, err := writeXrffInstances(attr.RelationValue(int(value)))
+				if err != nil {
+					return nil, err
+				}
+				x.Values[i].Instances = &xrffInstances{Instance: bag}
+			default:
+				x.Values[i].Text = strconv.FormatFloat(value, 'g', -1, 64)
+			}
+		}
+		xinsts[n] = x
+	}
+	return xinsts, nil
+}
@@ -0,0 +1,149 @@
+package data
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestXRFFRoundTrip(t *testing.T) {
+	for _, text := range []string{weatherARFF, reviewsARFF} {
+		insts := readTestARFF(t, text)
+		var buf bytes.Buffer
+		if err := WriteXRFF(&buf, insts); err != nil {
+			t.Fatal(err)
+		}
+		read, err := ReadXRFF(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if read.ClassIndex() != insts.ClassIndex() {
+			t.Errorf("class index %d, want %d", read.ClassIndex(), insts.ClassIndex())
+		}
+		if got, want := arffString(t, read), arffString(t, insts); got != want {
+			t.Errorf("read dataset differs:\n%s\nwant:\n%s", got, want)
+		}
+	}
+}
+
+//Attribute weights are kept and files ending with .gz are compressed
+func TestXRFFFile(t *testing.T) {
+	insts := readTestARFF(t, weatherARFF)
+	if err := insts.SetAttributeWeight(1, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "weather.xrff.gz")
+	if err := SaveXRFF(path, insts); err != nil {
+		t.Fatal(err)
+	}
+	read, err := LoadXRFF(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if weight := read.Attribute(1).Weight(); weight != 0.5 {
+		t.Errorf("attribute weight %v, want 0.5", weight)
+	}
+	if got, want := arffString(t, read), arffString(t, insts); got != want {
+		t.Errorf("read dataset differs:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+const sparseXRFF = `<?xml version="1.0" encoding="utf-8"?>
+<dataset name="mail">
+  <header>
+    <attributes>
+      <attribute name="subject" type="string"/>
+      <attribute name="links" type="numeric">
+        <metadata><property name="weight">2.5</property></metadata>
+      </attribute>
+      <attribute class="yes" name="spam" type="nominal">
+        <labels><label>no</label><label>yes &amp; maybe</label></labels>
+      </attribute>
+    </attributes>
+  </header>
+  <body>
+    <instances>
+      <instance weight="0.5"><value> Cheap &lt;pills&gt; </value><value>3</value><value>yes &amp; maybe</value></instance>
+      <instance type="sparse"><value index="2">4</value></instance>
+      <instance type="sparse" weight="2"><value index="1">hi</value><value index="3">?</value></instance>
+    </instances>
+  </body>
+</dataset>
+`
+
+func TestReadXRFFSparseAndWeights(t *testing.T) {
+	insts, err := ReadXRFF(strings.NewReader(sparseXRFF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if insts.DatasetName() != "mail" || insts.ClassIndex() != 2 || len(insts.Instances()) != 3 {
+		t.Fatalf("read %q with class %d and %d instances", insts.DatasetName(), insts.ClassIndex(), len(insts.Instances()))
+	}
+	if links := insts.Attribute(1); links.Weight() != 2.5 {
+		t.Errorf("attribute weight %v, want 2.5", links.Weight())
+	}
+	first, second, third := insts.Instance(0), insts.Instance(1), insts.Instance(2)
+	//string values keep their spaces
+	if subject := insts.Attribute(0); subject.Values()[int(first.Value(0))] != " Cheap <pills> " {
+		t.Errorf("subject %q", subject.Values()[int(first.Value(0))])
+	}
+	if first.IsSparse() || first.Weight() != 0.5 || first.Value(2) != 1 {
+		t.Errorf("first instance %v with weight %v", first, first.Weight())
+	}
+	if !second.IsSparse() || second.Weight() != 1 || second.Value(1) != 4 || second.Value(2) != 0 {
+		t.Errorf("second instance %v with weight %v", second, second.Weight())
+	}
+	if third.Weight() != 2 || !third.IsMissingValue(2) || third.Value(1) != 0 {
+		t.Errorf("third instance %v with weight %v", third, third.Weight())
+	}
+	var buf bytes.Buffer
+	if err := WriteXRFF(&buf, insts); err != nil {
+		t.Fatal(err)
+	}
+	for _, element := range []string{`<property name="weight">2.5</property>`, `weight="0.5"`, `<label>yes &amp; maybe</label>`, `&lt;pills&gt;`} {
+		if !strings.Contains(buf.String(), element) {
+			t.Errorf("WriteXRFF output does not contain %s:\n%s", element, buf.String())
+		}
+	}
+	read, err := ReadXRFF(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := arffString(t, read), arffString(t, insts); got != want {
+		t.Errorf("read dataset differs:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestReadXRFFErrors(t *testing.T) {
+	header := `<dataset name="d"><header><attributes>
+<attribute name="a" type="numeric"/><attribute name="b" type="nominal" class="yes"><labels><label>x</label></labels></attribute>
+</attributes></header><body><instances>`
+	footer := `</instances></body></dataset>`
+	for _, body := range []string{
+		`<instance><value>1</value></instance>`,
+		`<instance><value>1</value><value>z</value></instance>`,
+		`<instance weight="heavy"><value>1</value><value>x</value></instance>`,
+		`<instance type="sparse"><value index="3">1</value></instance>`,
+		`<instance type="sparse"><value index="2">x</value><value index="1">1</value></instance>`,
+		`<instance><value>one</value><value>x</value></instance>`,
+	} {
+		if _, err := ReadXRFF(strings.NewReader(header + body + footer)); err == nil {
+			t.Errorf("ReadXRFF read %s", body)
+		}
+	}
+	for _, attributes := range []string{
+		`<attribute name="a" type="numeric" class="yes"/><attribute name="b" type="numeric" class="yes"/>`,
+		`<attribute name="a" type="complex"/>`,
+		`<attribute name="a" type="nominal"><labels><label>x</label><label>x</label></labels></attribute>`,
+		`<attribute name="a" type="numeric"><metadata><property name="weight">heavy</property></metadata></attribute>`,
+	} {
+		text := `<dataset name="d"><header><attributes>` + attributes + `</attributes></header><body><instances/></body></dataset>`
+		if _, err := ReadXRFF(strings.NewReader(text)); err == nil {
+			t.Errorf("ReadXRFF read the attributes %s", attributes)
+		}
+	}
+	if _, err := ReadXRFF(strings.NewReader("<dataset><header>")); err == nil {
+		t.Errorf("ReadXRFF read truncated XML")
+	}
+}
@@ -0,0 +1,162 @@
+package evaluation
+
+import (
+	"fmt"
+	"github.com/project-mac/src/classifiers"
+	"github.com/project-mac/src/data"
+	"math/rand"
+	"sort"
+)
+
+//The error estimates computed by a .632+ bootstrap evaluation
+type Bootstrap struct {
+	//Number of bootstrap samples drawn
+	NumBootstraps int
+	//Error of the classifier trained and tested on the whole dataset
+	ResubstitutionError float64
+	//Leave-one-out bootstrap error, every instance is only evaluated by the
+	//classifiers whose bootstrap sample did not contain it
+	LeaveOneOutError float64
+	//No-information error rate, the error expected if the predictions were
+	//independent of the actual classes
+	NoInformationError float64
+	//Relative overfitting rate, between 0 and 1
+	RelativeOverfitting float64
+	//The .632 and .632+ estimates of the error rate
+	Error632, Error632Plus float64
+}
+
+//Performs the .632+ bootstrap evaluation (Efron & Tibshirani, 1997) of the
+//classifiers built by factory. Bootstrap samples are drawn with probability
+//proportional to the instances' weights and the out-of-bag predictions, which
+//are weighted by the instances' weights, are accumulated in the evaluation
+func (e *Evaluation) Bootstrap632Plus(factory classifiers.Factory, instances data.Instances, numBootstraps, seed int) (Bootstrap, error) {
+	var b Bootstrap
+	b.NumBootstraps = numBootstraps
+	numInstances := len(instances.Instances())
+	if numBootstraps < 1 {
+		return b, fmt.Errorf("Evaluation: the number of bootstrap samples must be at least 1")
+	}
+	if numInstances == 0 {
+		return b, fmt.Errorf("Evaluation: no instances to bootstrap")
+	}
+	if instances.ClassIndex() < 0 {
+		return b, data.ErrClassNotSet
+	}
+	// cumulative weights to sample with replacement according to the weights
+	cumWeights := make([]float64, numInstances)
+	sum := 0.0
+	for i, inst := range instances.Instances() {
+		sum += inst.Weight()
+		cumWeights[i] = sum
+	}
+	if sum <= 0 {
+		return b, fmt.Errorf("Evaluation: the sum of the instances' weights is not positive")
+	}
+	// per instance sum of out-of-bag errors and number of out-of-bag predictions
+	oobErrors := make([]float64, numInstances)
+	oobCounts := make([]float64, numInstances)
+	rnd := rand.New(rand.NewSource(int64(seed)))
+	inBag := make([]bool, numInstances)
+	for k := 0; k < numBootstraps; k++ {
+		for i := range inBag {
+			inBag[i] = false
+		}
+		sample := data.NewInstancesWithInst(instances, numInstances)
+		insts := sample.Instances()
+		for j := 0; j < numInstances; j++ {
+			idx := sort.SearchFloat64s(cumWeights, rnd.Float64()*sum)
+			if idx >= numInstances {
+				idx = numInstances - 1
+			}
+			inBag[idx] = true
+			inst := instances.Instance(idx).Copy()
+			inst.SetWeight(1)
+			insts = append(insts, inst)
+		}
+		sample.SetInstances(insts)
+		cls := factory()
+		if err := cls.BuildClassifier(sample); err != nil {
+			return b, err
+		}
+		for i := range instances.Instances() {
+			inst := instances.Instance(i)
+			if inBag[i] || inst.ClassMissing(e.classIndex) {
+				continue
+			}
+			pred, err := e.evaluateModelOnce(cls, inst, i)
+			if err != nil {
+				return b, err
+			}
+			oobCounts[i]++
+			if int(pred) != int(inst.ClassValue(e.classIndex)) {
+				oobErrors[i]++
+			}
+		}
+	}
+	// leave-one-out bootstrap error over the instances predicted at least once
+	errSum, weightSum := 0.0, 0.0
+	for i := range instances.Instances() {
+		if oobCounts[i] > 0 {
+			weight := instances.Instance(i).Weight()
+			errSum += weight * oobErrors[i] / oobCounts[i]
+			weightSum += weight
+		}
+	}
+	if weightSum > 0 {
+		b.LeaveOneOutError = errSum / weightSum
+	}
+	// resubstitution error and the class proportions needed for the
+	// no-information error rate
+	cls := factory()
+	if err := cls.BuildClassifier(instances); err != nil {
+		return b, err
+	}
+	actualProps := make([]float64, e.numClasses)
+	predictedProps := make([]float64, e.numClasses)
+	resubErr, total := 0.0, 0.0
+	for _, inst := range instances.Instances() {
+		if inst.ClassMissing(e.classIndex) {
+			continue
+		}
+		actual := int(inst.ClassValue(e.classIndex))
+		predicted := e.predictedClass(cls.DistributionForInstance(inst))
+		actualProps[actual] += inst.Weight()
+		if predicted >= 0 {
+			predictedProps[predicted] += inst.Weight()
+		}
+		if predicted != actual {
+			resubErr += inst.Weight()
+		}
+		total += inst.Weight()
+	}
+	if total == 0 {
+		return b, fmt.Errorf("Evaluation: no instances with a class value")
+	}
+	b.ResubstitutionError = resubErr / total
+	for i := range actualProps {
+		b.NoInformationError += (actualProps[i] / total) * (1 - predictedProps[i]/total)
+	}
+	b.Error632 = 0.368*b.ResubstitutionError + 0.632*b.LeaveOneOutError
+	looErr := b.LeaveOneOutError
+	if looErr > b.NoInformationError {
+		looErr = b.NoInformationError
+	}
+	if looErr > b.ResubstitutionError && b.NoInformationError > b.ResubstitutionError {
+		b.RelativeOverfitting = (looErr - b.ResubstitutionError) / (b.NoInformationError - b.ResubstitutionError)
+	}
+	weight := 0.632 / (1 - 0.368*b.RelativeOverfitting)
+	b.Error632Plus = (1-weight)*b.ResubstitutionError + weight*looErr
+	return b, nil
+}
+
+//Outputs the bootstrap estimates in summary form
+func (b Bootstrap) String() string {
+	return fmt.Sprintf("Bootstrap samples                  %12d\n", b.NumBootstraps) +
+		fmt.Sprintf("Resubstitution error               %12.4f\n", b.ResubstitutionError) +
+		fmt.Sprintf("Leave-one-out bootstrap error      %12.4f\n", b.LeaveOneOutError) +
+		fmt.Sprintf("No-information error rate          %12.4f\n", b.NoInformationError) +
+		fmt.Sprintf("Relative overfitting rate          %12.4f\n", b.RelativeOverfitting) +
+		fmt.Sprintf(".632 bootstrap error               %12.4f\n", b.Error632) +
+		fmt.Sprintf(".632+ bootstrap error              %12.4f\n", b.Error632Plus)
+}
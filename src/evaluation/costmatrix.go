@@ -0,0 +1,189 @@
+package evaluation
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/project-mac/src/data"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//Matrix of misclassification costs, cost[actual][predicted]
+type CostMatrix struct {
+	cost [][]float64
+}
+
+//Creates a cost matrix for numClasses classes with zero cost for correct
+//predictions and a cost of one for every error
+func NewCostMatrix(numClasses int) CostMatrix {
+	var cm CostMatrix
+	cm.cost = make([][]float64, numClasses)
+	for i := range cm.cost {
+		cm.cost[i] = make([]float64, numClasses)
+		for j := range cm.cost[i] {
+			if i != j {
+				cm.cost[i][j] = 1
+			}
+		}
+	}
+	return cm
+}
+
+//Reads a cost matrix for the values of the nominal class attribute. Every
+//line holds the actual class value, the predicted class value and the cost,
+//separated by spaces or commas; values containing spaces must be quoted.
+//Lines starting with % or # are comments. Entries not in the file keep the
+//default costs of NewCostMatrix. Example:
+//
+//	% actual    predicted  cost
+//	complaint   praise     10
+//	praise      complaint  1
+func ReadCostMatrix(reader io.Reader, classAttr *data.Attribute) (CostMatrix, error) {
+	if !classAttr.IsNominal() {
+		return CostMatrix{}, fmt.Errorf("CostMatrix: class attribute '%s' is not nominal", classAttr.Name())
+	}
+	cm := NewCostMatrix(len(classAttr.Values()))
+	scanner := bufio.NewScanner(reader)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "%") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields, err := splitCostFields(line)
+		if err != nil {
+			return cm, fmt.Errorf("CostMatrix: line %d: %s", lineNum, err.Error())
+		}
+		if len(fields) != 3 {
+			return cm, fmt.Errorf("CostMatrix: line %d: expected 'actual predicted cost', found %d fields", lineNum, len(fields))
+		}
+		actual, present := classAttr.ValuesIndexes()[fields[0]]
+		if !present {
+			return cm, fmt.Errorf("CostMatrix: line %d: unknown class value '%s'", lineNum, fields[0])
+		}
+		predicted, present := classAttr.ValuesIndexes()[fields[1]]
+		if !present {
+			return cm, fmt.Errorf("CostMatrix: line %d: unknown class value '%s'", lineNum, fields[1])
+		}
+		cost, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil || math.IsNaN(cost) {
+			return cm, fmt.Errorf("CostMatrix: line %d: bad cost '%s'", lineNum, fields[2])
+		}
+		cm.cost[actual][predicted] = cost
+	}
+	if err := scanner.Err(); err != nil {
+		return cm, err
+	}
+	return cm, nil
+}
+
+//Reads a cost matrix from a file, see ReadCostMatrix for the format
+func LoadCostMatrix(filepath string, classAttr *data.Attribute) (CostMatrix, error) {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return CostMatrix{}, err
+	}
+	defer file.Close()
+	return ReadCostMatrix(file, classAttr)
+}
+
+//Splits a line on spaces, tabs and commas, keeping quoted values together
+func splitCostFields(line string) ([]string, error) {
+	fields := make([]string, 0, 3)
+	var current strings.Builder
+	var quote rune
+	inField := false
+	for _, c := range line {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inField = true
+		case c == ' ' || c == '\t' || c == ',':
+			if inField {
+				fields = append(fields, current.String())
+				current.Reset()
+				inField = false
+			}
+		default:
+			current.WriteRune(c)
+			inField = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inField {
+		fields = append(fields, current.String())
+	}
+	return fields, nil
+}
+
+//Returns the expected cost of predicting each class given the class
+//probabilities
+func (cm *CostMatrix) ExpectedCosts(dist []float64) []float64 {
+	costs := make([]float64, len(cm.cost))
+	for j := range costs {
+		for i := range cm.cost {
+			if i < len(dist) {
+				costs[j] += dist[i] * cm.cost[i][j]
+			}
+		}
+	}
+	return costs
+}
+
+//Returns the class with the minimum expected cost, -1 if the distribution is
+//all zeros
+func (cm *CostMatrix) MinExpectedCostIndex(dist []float64) int {
+	sum := 0.0
+	for _, prob := range dist {
+		sum += prob
+	}
+	if sum <= 0 {
+		return -1
+	}
+	minIndex := -1
+	minCost := math.MaxFloat64
+	for j, cost := range cm.ExpectedCosts(dist) {
+		if cost < minCost {
+			minIndex = j
+			minCost = cost
+		}
+	}
+	return minIndex
+}
+
+func (cm *CostMatrix) NumClasses() int {
+	return len(cm.cost)
+}
+
+//Returns the cost of predicting predicted when the actual class is actual
+func (cm *CostMatrix) Element(actual, predicted int) float64 {
+	return cm.cost[actual][predicted]
+}
+
+func (cm *CostMatrix) SetElement(actual, predicted int, cost float64) {
+	cm.cost[actual][predicted] = cost
+}
+
+//Outputs the matrix, rows are the actual classes
+func (cm *CostMatrix) String() string {
+	var sb strings.Builder
+	for i := range cm.cost {
+		for j := range cm.cost[i] {
+			fmt.Fprintf(&sb, " %10.4g", cm.cost[i][j])
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
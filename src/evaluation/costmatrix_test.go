@@ -0,0 +1,100 @@
+package evaluation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/project-mac/src/data"
+)
+
+const sentimentARFF = `@relation sentiment
+@attribute stars numeric
+@attribute class {praise,'bad complaint'}
+@data
+5,praise
+1,'bad complaint'
+4,praise
+`
+
+func TestReadCostMatrix(t *testing.T) {
+	insts := readTestARFF(t, sentimentARFF)
+	text := `% actual predicted cost
+# missing a complaint is expensive
+'bad complaint', praise, 10
+
+praise "bad complaint" 0.5
+`
+	cm, err := ReadCostMatrix(strings.NewReader(text), insts.Attribute(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cm.Element(1, 0) != 10 || cm.Element(0, 1) != 0.5 || cm.Element(0, 0) != 0 {
+		t.Errorf("cost matrix:\n%s", cm.String())
+	}
+	for _, bad := range []string{
+		"praise praise",
+		"praise 'bad complaint 1",
+		"praise neutral 1",
+		"happy praise 1",
+		"praise praise x",
+		"praise praise NaN",
+	} {
+		if _, err := ReadCostMatrix(strings.NewReader(bad), insts.Attribute(1)); err == nil {
+			t.Errorf("%q was read", bad)
+		}
+	}
+	if _, err := ReadCostMatrix(strings.NewReader(text), insts.Attribute(0)); err == nil {
+		t.Errorf("a cost matrix for a numeric class was read")
+	}
+}
+
+//Predicts praise with probability 0.7
+type mostlyPraise struct{}
+
+func (mostlyPraise) BuildClassifier(instances data.Instances) error {
+	return nil
+}
+
+func (mostlyPraise) DistributionForInstance(instance data.Instance) []float64 {
+	return []float64{0.7, 0.3}
+}
+
+//Predicting praise costs 0.3*10 = 3 on average and a complaint 0.7*1, so the
+//cost-sensitive prediction is the complaint while the most probable class is
+//praise
+func TestMinimizeExpectedCost(t *testing.T) {
+	insts := readTestARFF(t, sentimentARFF)
+	cm := NewCostMatrix(2)
+	cm.SetElement(1, 0, 10)
+	for _, minimize := range []bool{false, true} {
+		e, err := NewEvaluation(insts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := e.SetCostMatrix(&cm); err != nil {
+			t.Fatal(err)
+		}
+		e.SetMinimizeExpectedCost(minimize)
+		predictions, err := e.EvaluateModel(mostlyPraise{}, insts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, cost := 0.0, 10.0
+		if minimize {
+			want, cost = 1, 2
+		}
+		for i, pred := range predictions {
+			if pred != want {
+				t.Errorf("minimize %v: prediction %d = %v, want %v", minimize, i, pred, want)
+			}
+		}
+		if e.TotalCost() != cost {
+			t.Errorf("minimize %v: total cost %v, want %v", minimize, e.TotalCost(), cost)
+		}
+	}
+	wrong := NewCostMatrix(3)
+	e, _ := NewEvaluation(insts)
+	if err := e.SetCostMatrix(&wrong); err == nil {
+		t.Errorf("a cost matrix with 3 classes was accepted for 2")
+	}
+}
@@ -0,0 +1,48 @@
+package evaluation
+
+import (
+	"reflect"
+	"testing"
+)
+
+//Runs with the same seed give the same folds, so the same statistics
+func TestCrossValidationSeed(t *testing.T) {
+	insts := readTestARFF(t, weatherARFF)
+	matrices := make([][][]float64, 0)
+	for _, seed := range []int{3, 3, 4, 4} {
+		e, err := NewEvaluation(insts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := e.CrossValidateModel(newFirstClass, insts, 5, seed); err != nil {
+			t.Fatal(err)
+		}
+		matrices = append(matrices, e.ConfusionMatrix())
+	}
+	if !reflect.DeepEqual(matrices[0], matrices[1]) || !reflect.DeepEqual(matrices[2], matrices[3]) {
+		t.Errorf("confusion matrices with the same seed differ: %v", matrices)
+	}
+}
+
+func TestLearningCurveSeed(t *testing.T) {
+	insts := readTestARFF(t, weatherARFF)
+	curves := make([][]float64, 0)
+	for _, seed := range []int{7, 7} {
+		lc := NewLearningCurve()
+		lc.SetSeed(seed)
+		lc.SetNumFolds(3)
+		lc.SetFractions([]float64{0.3, 0.6, 1})
+		points, err := lc.Run(newFirstClass, insts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		curve := make([]float64, 0, 2*len(points))
+		for _, point := range points {
+			curve = append(curve, point.NumTrain, point.Evaluation.ErrorRate())
+		}
+		curves = append(curves, curve)
+	}
+	if !reflect.DeepEqual(curves[0], curves[1]) {
+		t.Errorf("learning curves with the same seed differ: %v", curves)
+	}
+}
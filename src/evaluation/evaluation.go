@@ -0,0 +1,431 @@
+package evaluation
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/project-mac/src/classifiers"
+	"github.com/project-mac/src/data"
+	"github.com/project-mac/src/utils"
+	"math"
+)
+
+//Class for evaluating classifiers on a dataset with a nominal class, a
+//reduced port of weka's Evaluation
+type Evaluation struct {
+	//The header of the data the predictions are made for
+	header data.Instances
+	//Class attribute's index and number of class values
+	classIndex, numClasses int
+	//Weighted confusion matrix, confusionMatrix[actual][predicted]
+	confusionMatrix [][]float64
+	//Sum of weights of the instances with a class value, and of the
+	//correctly, incorrectly and not classified ones
+	withClass, correct, incorrect, unclassified float64
+	//Sum of weights of the instances with a missing class value
+	missingClass float64
+	//Sum of absolute and squared errors of the predicted distributions
+	sumAbsErr, sumSqrErr float64
+	//Where to write the per-instance predictions, nil for no output
+	predictionOutput *PredictionOutput
+	//The misclassification costs, nil if costs are not evaluated
+	costMatrix *CostMatrix
+	//Weighted sum of the misclassification costs
+	totalCost float64
+	//Predict the class with the minimum expected cost instead of the most
+	//probable one, needs a cost matrix
+	minimizeExpectedCost bool
+}
+
+//Creates an evaluation for datasets with the same header as the given one
+func NewEvaluation(header data.Instances) (Evaluation, error) {
+	var e Evaluation
+	e.header = data.NewInstancesWithInst(header, 0)
+	e.classIndex = header.ClassIndex()
+	if e.classIndex < 0 {
+		return e, data.ErrClassNotSet
+	}
+	if e.classIndex >= len(header.Attributes()) {
+		return e, fmt.Errorf("Evaluation: class index %d is not valid", e.classIndex)
+	}
+	classAttr := header.Attribute(e.classIndex)
+	if !classAttr.IsNominal() {
+		return e, fmt.Errorf("Evaluation: class attribute '%s' is not nominal", classAttr.Name())
+	}
+	e.numClasses = len(classAttr.Values())
+	e.confusionMatrix = make([][]float64, e.numClasses)
+	for i := range e.confusionMatrix {
+		e.confusionMatrix[i] = make([]float64, e.numClasses)
+	}
+	return e, nil
+}
+
+//Evaluates the classifier on a given set of instances and returns the
+//predicted class value of each instance, -1 for the unclassified ones
+func (e *Evaluation) EvaluateModel(cls classifiers.Classifier, test data.Instances) ([]float64, error) {
+	predictions := make([]float64, len(test.Instances()))
+	if err := e.header.Header().CheckCompatible(test.Header()); err != nil {
+		return predictions, fmt.Errorf("Evaluation: test set not compatible with the training set: %s", err.Error())
+	}
+	for i := range test.Instances() {
+		pred, err := e.evaluateModelOnce(cls, test.Instance(i), i)
+		if err != nil {
+			return predictions, err
+		}
+		predictions[i] = pred
+	}
+	return predictions, nil
+}
+
+//Evaluates the classifier on a single instance and records the prediction,
+//writing it to the prediction output if one is set
+func (e *Evaluation) EvaluateModelOnce(cls classifiers.Classifier, inst data.Instance) (float64, error) {
+	return e.evaluateModelOnce(cls, inst, -1)
+}
+
+//Evaluates the classifier on the instance at index in the evaluated data, -1
+//if unknown
+func (e *Evaluation) evaluateModelOnce(cls classifiers.Classifier, inst data.Instance, index int) (float64, error) {
+	dist := cls.DistributionForInstance(inst)
+	e.EvaluateDistribution(dist, inst)
+	predicted := e.predictedClass(dist)
+	if e.predictionOutput != nil {
+		if err := e.predictionOutput.PrintPrediction(e.header, index, dist, predicted, inst); err != nil {
+			return -1, err
+		}
+	}
+	return float64(predicted), nil
+}
+
+//Returns the class predicted from the distribution, the most probable one
+//or the one with the minimum expected cost, -1 if the distribution is all zeros
+//or the index is not a value of the class attribute (the distribution is
+//longer than the number of classes)
+func (e *Evaluation) predictedClass(dist []float64) int {
+	var predicted int
+	if e.minimizeExpectedCost && e.costMatrix != nil {
+		predicted = e.costMatrix.MinExpectedCostIndex(dist)
+	} else {
+		predicted = classifiers.MaxIndex(dist)
+	}
+	if predicted >= e.numClasses {
+		return -1
+	}
+	return predicted
+}
+
+//Updates the statistics with the predicted distribution for an instance
+func (e *Evaluation) EvaluateDistribution(dist []float64, inst data.Instance) {
+	weight := inst.Weight()
+	if inst.ClassMissing(e.classIndex) {
+		e.missingClass += weight
+		return
+	}
+	actual := int(inst.ClassValue(e.classIndex))
+	predicted := e.predictedClass(dist)
+	e.withClass += weight
+	if predicted < 0 {
+		e.unclassified += weight
+		return
+	}
+	e.confusionMatrix[actual][predicted] += weight
+	if e.costMatrix != nil {
+		e.totalCost += weight * e.costMatrix.Element(actual, predicted)
+	}
+	if predicted == actual {
+		e.correct += weight
+	} else {
+		e.incorrect += weight
+	}
+	absErr, sqrErr := 0.0, 0.0
+	for i := 0; i < e.numClasses; i++ {
+		prob := 0.0
+		if i < len(dist) {
+			prob = dist[i]
+		}
+		diff := prob
+		if i == actual {
+			diff = 1 - prob
+		}
+		absErr += math.Abs(diff)
+		sqrErr += diff * diff
+	}
+	e.sumAbsErr += weight * absErr / float64(e.numClasses)
+	e.sumSqrErr += weight * sqrErr / float64(e.numClasses)
+}
+
+//Performs a stratified cross-validation of the classifiers built by factory
+//on the given data, the statistics are accumulated in the evaluation
+func (e *Evaluation) CrossValidateModel(factory classifiers.Factory, instances data.Instances, numFolds, seed int) error {
+	if numFolds < 2 || numFolds > len(instances.Instances()) {
+		return fmt.Errorf("Evaluation: invalid number of folds %d for %d instances", numFolds, len(instances.Instances()))
+	}
+	cvData := copyInstances(instances)
+	cvData.Randomize(seed)
+	if err := cvData.Stratify(numFolds); err != nil {
+		return err
+	}
+	for i := 0; i < numFolds; i++ {
+		train, err := cvData.TrainCV(numFolds, i, seed)
+		if err != nil {
+			return err
+		}
+		test, err := cvData.TestCV(numFolds, i)
+		if err != nil {
+			return err
+		}
+		cls := factory()
+		if err := cls.BuildClassifier(train); err != nil {
+			return err
+		}
+		if _, err := e.EvaluateModel(cls, test); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Copies the instances' slice so that shuffling does not modify the original set
+func copyInstances(instances data.Instances) data.Instances {
+	c := data.NewInstancesWithInst(instances, len(instances.Instances()))
+	c.SetInstances(append(c.Instances(), instances.Instances()...))
+	return c
+}
+
+//Sets where to write the per-instance predictions, nil to disable the output
+func (e *Evaluation) SetPredictionOutput(output *PredictionOutput) {
+	e.predictionOutput = output
+}
+
+//Sets the misclassification costs, nil to stop evaluating costs
+func (e *Evaluation) SetCostMatrix(costMatrix *CostMatrix) error {
+	if costMatrix != nil && costMatrix.NumClasses() != e.numClasses {
+		return fmt.Errorf("Evaluation: cost matrix has %d classes, the class attribute has %d values", costMatrix.NumClasses(), e.numClasses)
+	}
+	e.costMatrix = costMatrix
+	return nil
+}
+
+//Sets whether to predict the class with the minimum expected cost according
+//to the cost matrix instead of the most probable class
+func (e *Evaluation) SetMinimizeExpectedCost(minimize bool) {
+	e.minimizeExpectedCost = minimize
+}
+
+//Gets methods
+
+func (e *Evaluation) PredictionOutput() *PredictionOutput {
+	return e.predictionOutput
+}
+
+func (e *Evaluation) CostMatrix() *CostMatrix {
+	return e.costMatrix
+}
+
+func (e *Evaluation) MinimizeExpectedCost() bool {
+	return e.minimizeExpectedCost
+}
+
+//Returns the weighted sum of the misclassification costs
+func (e *Evaluation) TotalCost() float64 {
+	return e.totalCost
+}
+
+//Returns the average misclassification cost per classified instance
+func (e *Evaluation) AvgCost() float64 {
+	if e.withClass-e.unclassified == 0 {
+		return 0
+	}
+	return e.totalCost / (e.withClass - e.unclassified)
+}
+
+func (e *Evaluation) NumInstances() float64 {
+	return e.withClass
+}
+
+func (e *Evaluation) Correct() float64 {
+	return e.correct
+}
+
+func (e *Evaluation) Incorrect() float64 {
+	return e.incorrect
+}
+
+func (e *Evaluation) Unclassified() float64 {
+	return e.unclassified
+}
+
+func (e *Evaluation) PctCorrect() float64 {
+	if e.withClass == 0 {
+		return 0
+	}
+	return 100 * e.correct / e.withClass
+}
+
+func (e *Evaluation) PctIncorrect() float64 {
+	if e.withClass == 0 {
+		return 0
+	}
+	return 100 * e.incorrect / e.withClass
+}
+
+func (e *Evaluation) PctUnclassified() float64 {
+	if e.withClass == 0 {
+		return 0
+	}
+	return 100 * e.unclassified / e.withClass
+}
+
+//Returns the error rate, unclassified instances are not counted
+func (e *Evaluation) ErrorRate() float64 {
+	if e.correct+e.incorrect == 0 {
+		return 0
+	}
+	return e.incorrect / (e.correct + e.incorrect)
+}
+
+func (e *Evaluation) MeanAbsoluteError() float64 {
+	if e.withClass-e.unclassified == 0 {
+		return 0
+	}
+	return e.sumAbsErr / (e.withClass - e.unclassified)
+}
+
+func (e *Evaluation) RootMeanSquaredError() float64 {
+	if e.withClass-e.unclassified == 0 {
+		return 0
+	}
+	return math.Sqrt(e.sumSqrErr / (e.withClass - e.unclassified))
+}
+
+func (e *Evaluation) ConfusionMatrix() [][]float64 {
+	return e.confusionMatrix
+}
+
+func (e *Evaluation) Header() data.Instances {
+	return e.header
+}
+
+//Returns the value of the kappa statistic
+func (e *Evaluation) Kappa() float64 {
+	sumRows := make([]float64, e.numClasses)
+	sumColumns := make([]float64, e.numClasses)
+	sumOfWeights := 0.0
+	for i := range e.confusionMatrix {
+		for j := range e.confusionMatrix[i] {
+			sumRows[i] += e.confusionMatrix[i][j]
+			sumColumns[j] += e.confusionMatrix[i][j]
+			sumOfWeights += e.confusionMatrix[i][j]
+		}
+	}
+	if sumOfWeights == 0 {
+		return 0
+	}
+	correct, chanceAgreement := 0.0, 0.0
+	for i := range e.confusionMatrix {
+		chanceAgreement += sumRows[i] * sumColumns[i]
+		correct += e.confusionMatrix[i][i]
+	}
+	chanceAgreement /= sumOfWeights * sumOfWeights
+	correct /= sumOfWeights
+	if chanceAgreement < 1 {
+		return (correct - chanceAgreement) / (1 - chanceAgreement)
+	}
+	return 1
+}
+
+//Returns the precision for the given class value
+func (e *Evaluation) Precision(classValue int) float64 {
+	correct, total := 0.0, 0.0
+	for i := range e.confusionMatrix {
+		if i == classValue {
+			correct += e.confusionMatrix[i][classValue]
+		}
+		total += e.confusionMatrix[i][classValue]
+	}
+	if total == 0 {
+		return 0
+	}
+	return correct / total
+}
+
+//Returns the recall for the given class value
+func (e *Evaluation) Recall(classValue int) float64 {
+	correct, total := 0.0, 0.0
+	for j := range e.confusionMatrix[classValue] {
+		if j == classValue {
+			correct += e.confusionMatrix[classValue][j]
+		}
+		total += e.confusionMatrix[classValue][j]
+	}
+	if total == 0 {
+		return 0
+	}
+	return correct / total
+}
+
+//Returns the F-Measure for the given class value
+func (e *Evaluation) FMeasure(classValue int) float64 {
+	precision := e.Precision(classValue)
+	recall := e.Recall(classValue)
+	if precision+recall == 0 {
+		return 0
+	}
+	return 2 * precision * recall / (precision + recall)
+}
+
+//Returns the F-Measure averaged over the classes, weighted by class frequency
+func (e *Evaluation) WeightedFMeasure() float64 {
+	fMeasureTotal, total := 0.0, 0.0
+	for i := range e.confusionMatrix {
+		classCount := 0.0
+		for j := range e.confusionMatrix[i] {
+			classCount += e.confusionMatrix[i][j]
+		}
+		fMeasureTotal += classCount * e.FMeasure(i)
+		total += classCount
+	}
+	if total == 0 {
+		return 0
+	}
+	return fMeasureTotal / total
+}
+
+//Outputs the performance statistics in summary form
+func (e *Evaluation) ToSummaryString() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Correctly Classified Instances     %12.4f %10.4f %%\n", e.correct, e.PctCorrect())
+	fmt.Fprintf(&buf, "Incorrectly Classified Instances   %12.4f %10.4f %%\n", e.incorrect, e.PctIncorrect())
+	fmt.Fprintf(&buf, "Kappa statistic                    %12.4f\n", e.Kappa())
+	fmt.Fprintf(&buf, "Mean absolute error                %12.4f\n", e.MeanAbsoluteError())
+	fmt.Fprintf(&buf, "Root mean squared error            %12.4f\n", e.RootMeanSquaredError())
+	if e.costMatrix != nil {
+		fmt.Fprintf(&buf, "Total Cost                         %12.4f\n", e.TotalCost())
+		fmt.Fprintf(&buf, "Average Cost                       %12.4f\n", e.AvgCost())
+	}
+	if utils.Gr(e.unclassified, 0) {
+		fmt.Fprintf(&buf, "UnClassified Instances             %12.4f %10.4f %%\n", e.unclassified, e.PctUnclassified())
+	}
+	fmt.Fprintf(&buf, "Total Number of Instances          %12.4f\n", e.withClass)
+	if utils.Gr(e.missingClass, 0) {
+		fmt.Fprintf(&buf, "Ignored Class Unknown Instances    %12.4f\n", e.missingClass)
+	}
+	return buf.String()
+}
+
+//Outputs the performance statistics as a classification confusion matrix
+func (e *Evaluation) ToMatrixString() string {
+	var buf bytes.Buffer
+	values := e.header.Attribute(e.classIndex).Values()
+	buf.WriteString("=== Confusion Matrix ===\n\n")
+	for i := range values {
+		fmt.Fprintf(&buf, " %10s", fmt.Sprint(i))
+	}
+	buf.WriteString("   <-- classified as\n")
+	for i := range e.confusionMatrix {
+		for j := range e.confusionMatrix[i] {
+			fmt.Fprintf(&buf, " %10.4g", e.confusionMatrix[i][j])
+		}
+		fmt.Fprintf(&buf, " | %d = %s\n", i, values[i])
+	}
+	return buf.String()
+}
@@ -0,0 +1,62 @@
+package evaluation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/project-mac/src/data"
+)
+
+const binaryARFF = `@relation test
+@attribute x numeric
+@attribute class {a,b}
+@data
+1,a
+2,b
+3,a
+`
+
+//Predicts a distribution over more values than the class has
+type longDistribution struct{}
+
+func (longDistribution) BuildClassifier(instances data.Instances) error {
+	return nil
+}
+
+func (longDistribution) DistributionForInstance(instance data.Instance) []float64 {
+	return []float64{0.1, 0.2, 0.7}
+}
+
+func TestPredictionOutOfRange(t *testing.T) {
+	insts, err := data.ReadARFF(strings.NewReader(binaryARFF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	insts.SetClassIndex(1)
+	e, err := NewEvaluation(insts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	predictions, err := e.EvaluateModel(longDistribution{}, insts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, pred := range predictions {
+		if pred != -1 {
+			t.Errorf("prediction %d = %v, want -1", i, pred)
+		}
+	}
+	if e.Unclassified() != 3 {
+		t.Errorf("unclassified = %v, want 3", e.Unclassified())
+	}
+}
+
+func TestClassNotSet(t *testing.T) {
+	insts, err := data.ReadARFF(strings.NewReader(binaryARFF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewEvaluation(insts); err != data.ErrClassNotSet {
+		t.Errorf("NewEvaluation without class: got %v", err)
+	}
+}
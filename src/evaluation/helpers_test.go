@@ -0,0 +1,88 @@
+package evaluation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/project-mac/src/classifiers"
+	"github.com/project-mac/src/data"
+)
+
+const weatherARFF = `@relation weather
+@attribute outlook {sunny,overcast,rainy}
+@attribute temperature numeric
+@attribute humidity numeric
+@attribute windy {TRUE,FALSE}
+@attribute play {yes,no}
+@data
+sunny,85,85,FALSE,no
+sunny,80,90,TRUE,no
+overcast,83,86,FALSE,yes
+rainy,70,96,FALSE,yes
+rainy,68,80,FALSE,yes
+rainy,65,70,TRUE,no
+overcast,64,65,TRUE,yes
+sunny,72,95,FALSE,no
+sunny,69,70,FALSE,yes
+rainy,75,80,FALSE,yes
+sunny,75,70,TRUE,yes
+overcast,72,90,TRUE,yes
+overcast,81,75,FALSE,yes
+rainy,71,91,TRUE,no
+`
+
+//Parses an ARFF dataset, the class is the last attribute
+func readTestARFF(t *testing.T, text string) data.Instances {
+	t.Helper()
+	insts, err := data.ReadARFF(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("ReadARFF: %v", err)
+	}
+	insts.SetClassIndex(len(insts.Attributes()) - 1)
+	return insts
+}
+
+//Predicts the class of the first training instance, so its predictions
+//depend on the order of the training data
+type firstClass struct {
+	class      int
+	numClasses int
+}
+
+func newFirstClass() classifiers.Classifier {
+	return new(firstClass)
+}
+
+func (fc *firstClass) BuildClassifier(instances data.Instances) error {
+	classIndex := instances.ClassIndex()
+	fc.numClasses = len(instances.Attribute(classIndex).Values())
+	fc.class = int(instances.Instance(0).ClassValue(classIndex))
+	return nil
+}
+
+func (fc *firstClass) DistributionForInstance(instance data.Instance) []float64 {
+	dist := make([]float64, fc.numClasses)
+	dist[fc.class] = 1
+	return dist
+}
+
+//Predicts the true class of the instances
+type oracle struct {
+	classIndex, numClasses int
+}
+
+func newOracle() classifiers.Classifier {
+	return new(oracle)
+}
+
+func (o *oracle) BuildClassifier(instances data.Instances) error {
+	o.classIndex = instances.ClassIndex()
+	o.numClasses = len(instances.Attribute(o.classIndex).Values())
+	return nil
+}
+
+func (o *oracle) DistributionForInstance(instance data.Instance) []float64 {
+	dist := make([]float64, o.numClasses)
+	dist[int(instance.ClassValue(o.classIndex))] = 1
+	return dist
+}
@@ -0,0 +1,37 @@
+package evaluation
+
+import (
+	"fmt"
+	"github.com/project-mac/src/classifiers"
+	"github.com/project-mac/src/data"
+	"math"
+)
+
+//Evaluates the classifier built by factory on a percentage split: the first
+//percentage of the instances is used for training and the rest for testing.
+//The instances are shuffled with seed first unless preserveOrder is set,
+//which is what time-ordered data needs
+func (e *Evaluation) EvaluateHoldout(factory classifiers.Factory, instances data.Instances, percentage float64, preserveOrder bool, seed int) error {
+	if percentage <= 0 || percentage >= 100 {
+		return fmt.Errorf("Evaluation: split percentage %g is not in (0, 100)", percentage)
+	}
+	numInstances := len(instances.Instances())
+	trainSize := int(math.Round(float64(numInstances) * percentage / 100))
+	if trainSize == 0 || trainSize == numInstances {
+		return fmt.Errorf("Evaluation: a %g%% split of %d instances leaves an empty training or test set", percentage, numInstances)
+	}
+	splitData := copyInstances(instances)
+	if !preserveOrder {
+		splitData.Randomize(seed)
+	}
+	train := data.NewInstancesWithInst(splitData, trainSize)
+	train.SetInstances(append(train.Instances(), splitData.Instances()[:trainSize]...))
+	test := data.NewInstancesWithInst(splitData, numInstances-trainSize)
+	test.SetInstances(append(test.Instances(), splitData.Instances()[trainSize:]...))
+	cls := factory()
+	if err := cls.BuildClassifier(train); err != nil {
+		return err
+	}
+	_, err := e.EvaluateModel(cls, test)
+	return err
+}
@@ -0,0 +1,118 @@
+package evaluation
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/project-mac/src/classifiers"
+	"github.com/project-mac/src/data"
+)
+
+func TestHoldout(t *testing.T) {
+	insts := readTestARFF(t, weatherARFF)
+	e, err := NewEvaluation(insts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//trained on the first 7 instances, the first one is a no
+	if err := e.EvaluateHoldout(newFirstClass, insts, 50, true, 1); err != nil {
+		t.Fatal(err)
+	}
+	if e.NumInstances() != 7 || e.Correct() != 2 {
+		t.Errorf("%v correct of %v, want 2 of 7", e.Correct(), e.NumInstances())
+	}
+	matrices := make([][][]float64, 0, 2)
+	for run := 0; run < 2; run++ {
+		e, _ := NewEvaluation(insts)
+		if err := e.EvaluateHoldout(newFirstClass, insts, 66, false, 5); err != nil {
+			t.Fatal(err)
+		}
+		matrices = append(matrices, e.ConfusionMatrix())
+	}
+	if !reflect.DeepEqual(matrices[0], matrices[1]) {
+		t.Errorf("splits with the same seed differ: %v", matrices)
+	}
+	if err := e.EvaluateHoldout(newFirstClass, insts, 100, false, 1); err == nil {
+		t.Errorf("a 100%% split did not fail")
+	}
+}
+
+//Predicts the class of the training instances with the same value of the
+//first attribute, the last class for instances it has not seen
+type memorizer struct {
+	classIndex, numClasses int
+	classes                map[float64]int
+}
+
+func newMemorizer() classifiers.Classifier {
+	return new(memorizer)
+}
+
+func (m *memorizer) BuildClassifier(instances data.Instances) error {
+	m.classIndex = instances.ClassIndex()
+	m.numClasses = len(instances.Attribute(m.classIndex).Values())
+	m.classes = make(map[float64]int)
+	for _, inst := range instances.Instances() {
+		m.classes[inst.Value(0)] = int(inst.ClassValue(m.classIndex))
+	}
+	return nil
+}
+
+func (m *memorizer) DistributionForInstance(instance data.Instance) []float64 {
+	dist := make([]float64, m.numClasses)
+	if class, seen := m.classes[instance.Value(0)]; seen {
+		dist[class] = 1
+	} else {
+		dist[m.numClasses-1] = 1
+	}
+	return dist
+}
+
+const balancedARFF = `@relation balanced
+@attribute id numeric
+@attribute class {a,b}
+@data
+1,a
+2,b
+3,a
+4,b
+5,a
+6,b
+7,a
+8,b
+`
+
+func TestBootstrap632Plus(t *testing.T) {
+	near := func(got, want float64) bool {
+		return math.Abs(got-want) < 1e-9
+	}
+	//an oracle makes no errors, the no-information error comes from the
+	//class proportions: 2 * 9/14 * 5/14
+	weather := readTestARFF(t, weatherARFF)
+	e, _ := NewEvaluation(weather)
+	b, err := e.Bootstrap632Plus(newOracle, weather, 20, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.ResubstitutionError != 0 || b.LeaveOneOutError != 0 || b.Error632Plus != 0 || !near(b.NoInformationError, 90.0/196) {
+		t.Errorf("oracle: %+v", b)
+	}
+	//the memorizer is right on its training data and predicts b for the
+	//rest, so it overfits completely: R = 1 and the .632+ weight is 1
+	balanced := readTestARFF(t, balancedARFF)
+	e, _ = NewEvaluation(balanced)
+	b, err = e.Bootstrap632Plus(newMemorizer, balanced, 200, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.ResubstitutionError != 0 || !near(b.LeaveOneOutError, 0.5) || !near(b.NoInformationError, 0.5) {
+		t.Errorf("memorizer errors: %+v", b)
+	}
+	if !near(b.RelativeOverfitting, 1) || !near(b.Error632, 0.316) || !near(b.Error632Plus, 0.5) {
+		t.Errorf("memorizer estimates: %+v", b)
+	}
+	if _, err := e.Bootstrap632Plus(newMemorizer, balanced, 0, 1); err == nil {
+		t.Errorf("0 bootstrap samples did not fail")
+	}
+}
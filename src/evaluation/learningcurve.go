@@ -0,0 +1,223 @@
+package evaluation
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"github.com/project-mac/src/classifiers"
+	"github.com/project-mac/src/data"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+//One point of a learning curve, the evaluation of the classifiers trained on
+//a fraction of the training data
+type LearningCurvePoint struct {
+	//Fraction of the training data used
+	Fraction float64
+	//Number of training instances used, averaged over the folds if the curve
+	//is computed with cross-validation
+	NumTrain float64
+	//The statistics on the test data
+	Evaluation Evaluation
+}
+
+//Generates learning curves: trains a classifier on increasing, nested and
+//stratified fractions of the training data and evaluates each one on a
+//held-out test set or by cross-validation
+type LearningCurve struct {
+	//The fractions of the training data to train on, in (0, 1]
+	fractions []float64
+	//Seed used for sub-sampling and cross-validation
+	seed int
+	//The number of folds used when no test set is given
+	numFolds int
+	//The held-out test set, if nil cross-validation is used
+	testSet *data.Instances
+}
+
+func NewLearningCurve() LearningCurve {
+	var lc LearningCurve
+	lc.fractions = []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 1.0}
+	lc.seed = 1
+	lc.numFolds = 10
+	return lc
+}
+
+//Computes the learning curve of the classifiers built by factory
+func (lc *LearningCurve) Run(factory classifiers.Factory, train data.Instances) ([]LearningCurvePoint, error) {
+	if len(lc.fractions) == 0 {
+		return nil, fmt.Errorf("LearningCurve: no fractions defined")
+	}
+	for _, f := range lc.fractions {
+		if f <= 0 || f > 1 {
+			return nil, fmt.Errorf("LearningCurve: fraction %g is not in (0, 1]", f)
+		}
+	}
+	fractions := make([]float64, len(lc.fractions))
+	copy(fractions, lc.fractions)
+	sort.Float64s(fractions)
+	points := make([]LearningCurvePoint, len(fractions))
+	for i, f := range fractions {
+		eval, err := NewEvaluation(train)
+		if err != nil {
+			return nil, err
+		}
+		points[i].Fraction = f
+		points[i].Evaluation = eval
+	}
+	if lc.testSet != nil {
+		if err := lc.evaluateSplit(factory, train, *lc.testSet, points); err != nil {
+			return nil, err
+		}
+		return points, nil
+	}
+	if lc.numFolds < 2 || lc.numFolds > len(train.Instances()) {
+		return nil, fmt.Errorf("LearningCurve: invalid number of folds %d for %d instances", lc.numFolds, len(train.Instances()))
+	}
+	cvData := copyInstances(train)
+	cvData.Randomize(lc.seed)
+	if err := cvData.Stratify(lc.numFolds); err != nil {
+		return nil, err
+	}
+	for fold := 0; fold < lc.numFolds; fold++ {
+		trainFold, err := cvData.TrainCV(lc.numFolds, fold, lc.seed)
+		if err != nil {
+			return nil, err
+		}
+		testFold, err := cvData.TestCV(lc.numFolds, fold)
+		if err != nil {
+			return nil, err
+		}
+		if err := lc.evaluateSplit(factory, trainFold, testFold, points); err != nil {
+			return nil, err
+		}
+	}
+	for i := range points {
+		points[i].NumTrain /= float64(lc.numFolds)
+	}
+	return points, nil
+}
+
+//Trains on every fraction of train and accumulates the statistics on test
+func (lc *LearningCurve) evaluateSplit(factory classifiers.Factory, train, test data.Instances, points []LearningCurvePoint) error {
+	strata := lc.strata(train)
+	for i := range points {
+		subset := subsample(train, strata, points[i].Fraction)
+		cls := factory()
+		if err := cls.BuildClassifier(subset); err != nil {
+			return err
+		}
+		if _, err := points[i].Evaluation.EvaluateModel(cls, test); err != nil {
+			return err
+		}
+		points[i].NumTrain += float64(len(subset.Instances()))
+	}
+	return nil
+}
+
+//Groups the instances' indexes by class value and shuffles every group, the
+//instances with a missing class form a group of their own
+func (lc *LearningCurve) strata(train data.Instances) [][]int {
+	classIndex := train.ClassIndex()
+	numClasses := len(train.Attribute(classIndex).Values())
+	strata := make([][]int, numClasses+1)
+	for i := range train.Instances() {
+		inst := train.Instance(i)
+		if inst.ClassMissing(classIndex) {
+			strata[numClasses] = append(strata[numClasses], i)
+		} else {
+			c := int(inst.ClassValue(classIndex))
+			strata[c] = append(strata[c], i)
+		}
+	}
+	rnd := rand.New(rand.NewSource(int64(lc.seed)))
+	for _, s := range strata {
+		rnd.Shuffle(len(s), func(j, k int) { s[j], s[k] = s[k], s[j] })
+	}
+	return strata
+}
+
+//Takes the same fraction of every stratum, smaller fractions are subsets of
+//bigger ones because the strata are shuffled only once
+func subsample(train data.Instances, strata [][]int, fraction float64) data.Instances {
+	selected := make([]int, 0, int(fraction*float64(len(train.Instances())))+len(strata))
+	for _, s := range strata {
+		n := int(math.Round(fraction * float64(len(s))))
+		if n == 0 && len(s) > 0 {
+			n = 1
+		}
+		selected = append(selected, s[:n]...)
+	}
+	sort.Ints(selected)
+	subset := data.NewInstancesWithInst(train, len(selected))
+	insts := subset.Instances()
+	for _, idx := range selected {
+		insts = append(insts, train.Instance(idx))
+	}
+	subset.SetInstances(insts)
+	return subset
+}
+
+//Outputs the learning curve as a text table
+func LearningCurveTable(points []LearningCurvePoint) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%9s %10s %10s %10s %10s %10s %10s %10s\n",
+		"Fraction", "NumTrain", "PctCorrect", "ErrorRate", "Kappa", "WeightedF", "MAE", "RMSE")
+	for _, p := range points {
+		e := p.Evaluation
+		fmt.Fprintf(&buf, "%9.4f %10.2f %10.4f %10.4f %10.4f %10.4f %10.4f %10.4f\n",
+			p.Fraction, p.NumTrain, e.PctCorrect(), e.ErrorRate(), e.Kappa(),
+			e.WeightedFMeasure(), e.MeanAbsoluteError(), e.RootMeanSquaredError())
+	}
+	return buf.String()
+}
+
+//Writes the learning curve as CSV with a header row
+func WriteLearningCurveCSV(w io.Writer, points []LearningCurvePoint) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"fraction", "num_train", "pct_correct", "error_rate", "kappa", "weighted_f_measure", "mean_absolute_error", "root_mean_squared_error"})
+	for _, p := range points {
+		e := p.Evaluation
+		writer.Write([]string{fmt.Sprint(p.Fraction), fmt.Sprint(p.NumTrain), fmt.Sprint(e.PctCorrect()),
+			fmt.Sprint(e.ErrorRate()), fmt.Sprint(e.Kappa()), fmt.Sprint(e.WeightedFMeasure()),
+			fmt.Sprint(e.MeanAbsoluteError()), fmt.Sprint(e.RootMeanSquaredError())})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+//Sets methods
+
+func (lc *LearningCurve) SetFractions(fractions []float64) {
+	lc.fractions = fractions
+}
+
+func (lc *LearningCurve) SetSeed(seed int) {
+	lc.seed = seed
+}
+
+func (lc *LearningCurve) SetNumFolds(numFolds int) {
+	lc.numFolds = numFolds
+}
+
+//Sets the held-out test set, nil to use cross-validation
+func (lc *LearningCurve) SetTestSet(test *data.Instances) {
+	lc.testSet = test
+}
+
+//Gets methods
+
+func (lc *LearningCurve) Fractions() []float64 {
+	return lc.fractions
+}
+
+func (lc *LearningCurve) Seed() int {
+	return lc.seed
+}
+
+func (lc *LearningCurve) NumFolds() int {
+	return lc.numFolds
+}
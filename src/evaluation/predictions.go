@@ -0,0 +1,278 @@
+package evaluation
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/project-mac/src/data"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//Formats for the per-instance predictions
+const (
+	PREDICTIONS_CSV   = 0
+	PREDICTIONS_ARFF  = 1
+	PREDICTIONS_JSONL = 2
+)
+
+//Writes one record per evaluated instance: its position in the evaluated
+//data, actual and predicted class, an error flag, the predicted distribution
+//and optionally the value of an ID attribute
+type PredictionOutput struct {
+	writer io.Writer
+	csvWriter *csv.Writer
+	//One of PREDICTIONS_CSV, PREDICTIONS_ARFF or PREDICTIONS_JSONL
+	format int
+	//The header of the evaluated data
+	header data.Instances
+	//Index of the attribute whose value identifies each instance, -1 for none
+	idIndex int
+	//Whether to output the predicted distribution
+	outputDistribution bool
+	//Number of predictions written so far
+	numPredictions int
+	headerWritten bool
+}
+
+//A prediction record as written in JSON Lines format
+type jsonPrediction struct {
+	Inst         int                `json:"inst"`
+	Actual       *string            `json:"actual"`
+	Predicted    *string            `json:"predicted"`
+	Error        bool               `json:"error"`
+	Distribution map[string]float64 `json:"distribution,omitempty"`
+	ID           *string            `json:"id,omitempty"`
+}
+
+func NewPredictionOutput(writer io.Writer, format int) PredictionOutput {
+	var po PredictionOutput
+	po.writer = writer
+	po.format = format
+	po.idIndex = -1
+	po.outputDistribution = true
+	return po
+}
+
+//Writes the record for one instance given its index in the evaluated data,
+//written counting from 1 (-1 if unknown, then the number of the prediction is
+//written), and its predicted distribution and class (-1 if unclassified). The
+//header is written before the first record
+func (po *PredictionOutput) PrintPrediction(header data.Instances, index int, dist []float64, predicted int, inst data.Instance) error {
+	if !po.headerWritten {
+		if header.ClassIndex() < 0 {
+			return data.ErrClassNotSet
+		}
+		if po.idIndex >= len(header.Attributes()) {
+			return fmt.Errorf("PredictionOutput: ID attribute %d out of range, there are %d attributes", po.idIndex, len(header.Attributes()))
+		}
+		po.header = header
+		if err := po.printHeader(); err != nil {
+			return err
+		}
+		po.headerWritten = true
+	}
+	po.numPredictions++
+	number := index + 1
+	if index < 0 {
+		number = po.numPredictions
+	}
+	classIndex := po.header.ClassIndex()
+	classValues := po.header.Attribute(classIndex).Values()
+	var actual, pred, id *string
+	if !inst.ClassMissing(classIndex) {
+		actual = &classValues[int(inst.ClassValue(classIndex))]
+	}
+	if predicted >= 0 {
+		pred = &classValues[predicted]
+	}
+	isError := actual != nil && pred != nil && *actual != *pred
+	if po.idIndex >= 0 {
+		id = valueString(po.header.Attribute(po.idIndex), inst, po.idIndex)
+	}
+	switch po.format {
+	case PREDICTIONS_CSV:
+		record := []string{strconv.Itoa(number), stringOrMissing(actual), stringOrMissing(pred), ""}
+		if isError {
+			record[3] = "+"
+		}
+		if po.outputDistribution {
+			for i := range classValues {
+				record = append(record, formatProb(dist, i))
+			}
+		}
+		if po.idIndex >= 0 {
+			record = append(record, stringOrMissing(id))
+		}
+		po.csvWriter.Write(record)
+		po.csvWriter.Flush()
+		return po.csvWriter.Error()
+	case PREDICTIONS_ARFF:
+		record := []string{strconv.Itoa(number), quoteOrMissing(actual), quoteOrMissing(pred), "no"}
+		if isError {
+			record[3] = "yes"
+		}
+		if po.outputDistribution {
+			for i := range classValues {
+				record = append(record, formatProb(dist, i))
+			}
+		}
+		if po.idIndex >= 0 {
+			record = append(record, quoteOrMissing(id))
+		}
+		_, err := fmt.Fprintln(po.writer, strings.Join(record, ","))
+		return err
+	case PREDICTIONS_JSONL:
+		p := jsonPrediction{Inst: number, Actual: actual, Predicted: pred, Error: isError, ID: id}
+		if po.outputDistribution {
+			p.Distribution = make(map[string]float64, len(classValues))
+			for i, value := range classValues {
+				p.Distribution[value] = prob(dist, i)
+			}
+		}
+		line, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(po.writer, "%s\n", line)
+		return err
+	}
+	return fmt.Errorf("PredictionOutput: unknown format %d", po.format)
+}
+
+//Writes the column names for CSV or the ARFF header
+func (po *PredictionOutput) printHeader() error {
+	classIndex := po.header.ClassIndex()
+	classValues := po.header.Attribute(classIndex).Values()
+	switch po.format {
+	case PREDICTIONS_CSV:
+		po.csvWriter = csv.NewWriter(po.writer)
+		record := []string{"inst", "actual", "predicted", "error"}
+		if po.outputDistribution {
+			for _, value := range classValues {
+				record = append(record, "prob_"+value)
+			}
+		}
+		if po.idIndex >= 0 {
+			record = append(record, po.header.Attribute(po.idIndex).Name())
+		}
+		po.csvWriter.Write(record)
+		po.csvWriter.Flush()
+		return po.csvWriter.Error()
+	case PREDICTIONS_ARFF:
+		quoted := make([]string, len(classValues))
+		for i, value := range classValues {
+			quoted[i] = data.Quote(value)
+		}
+		nominal := "{" + strings.Join(quoted, ",") + "}"
+		lines := []string{"@relation " + data.Quote(po.header.DatasetName()+"_predictions"), "",
+			"@attribute inst numeric",
+			"@attribute actual " + nominal,
+			"@attribute predicted " + nominal,
+			"@attribute error {no,yes}"}
+		if po.outputDistribution {
+			for _, value := range classValues {
+				lines = append(lines, "@attribute "+data.Quote("prob_"+value)+" numeric")
+			}
+		}
+		if po.idIndex >= 0 {
+			lines = append(lines, "@attribute "+data.Quote(po.header.Attribute(po.idIndex).Name())+" string")
+		}
+		lines = append(lines, "", "@data")
+		_, err := fmt.Fprintln(po.writer, strings.Join(lines, "\n"))
+		return err
+	case PREDICTIONS_JSONL:
+		return nil
+	}
+	return fmt.Errorf("PredictionOutput: unknown format %d", po.format)
+}
+
+//Returns the probability of the class value, zero if not predicted
+func prob(dist []float64, i int) float64 {
+	if i < len(dist) {
+		return dist[i]
+	}
+	return 0
+}
+
+func formatProb(dist []float64, i int) string {
+	return strconv.FormatFloat(prob(dist, i), 'g', -1, 64)
+}
+
+func stringOrMissing(s *string) string {
+	if s == nil {
+		return "?"
+	}
+	return *s
+}
+
+//Returns the value quoted for ARFF, "?" if it is missing
+func quoteOrMissing(s *string) string {
+	if s == nil {
+		return "?"
+	}
+	return data.Quote(*s)
+}
+
+//Returns the value of the attribute in the instance as a string, nil if
+//missing
+func valueString(attr *data.Attribute, inst data.Instance, idx int) *string {
+	val := inst.Value(idx)
+	if math.IsNaN(val) {
+		return nil
+	}
+	var text string
+	switch {
+	case attr.IsNominal() || attr.IsString():
+		text = attr.Values()[int(val)]
+	case attr.Type() == data.DATE:
+		text = attr.FormatDate(val)
+	default:
+		text = strconv.FormatFloat(val, 'g', -1, 64)
+	}
+	return &text
+}
+
+//Sets methods
+
+//Sets the index of the attribute that identifies each instance, -1 for none.
+//It can not be changed once a prediction has been written
+func (po *PredictionOutput) SetIDAttribute(idIndex int) error {
+	if idIndex < -1 {
+		return fmt.Errorf("PredictionOutput: bad ID attribute index %d", idIndex)
+	}
+	if po.headerWritten {
+		if idIndex >= len(po.header.Attributes()) {
+			return fmt.Errorf("PredictionOutput: ID attribute %d out of range, there are %d attributes", idIndex, len(po.header.Attributes()))
+		}
+		if idIndex != po.idIndex {
+			return fmt.Errorf("PredictionOutput: the ID attribute can't be changed after the header is written")
+		}
+	}
+	po.idIndex = idIndex
+	return nil
+}
+
+func (po *PredictionOutput) SetOutputDistribution(output bool) {
+	po.outputDistribution = output
+}
+
+//Gets methods
+
+func (po *PredictionOutput) Format() int {
+	return po.format
+}
+
+func (po *PredictionOutput) IDAttribute() int {
+	return po.idIndex
+}
+
+func (po *PredictionOutput) OutputDistribution() bool {
+	return po.outputDistribution
+}
+
+func (po *PredictionOutput) NumPredictions() int {
+	return po.numPredictions
+}
@@ -0,0 +1,128 @@
+package evaluation
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/project-mac/src/data"
+)
+
+//A class with a value named "?" and a date ID attribute
+const idsARFF = `@relation ids
+@attribute name string
+@attribute when date "yyyy-MM-dd"
+@attribute class {yes,'?'}
+@data
+'a b',2024-01-02,yes
+c,?,'?'
+d,2024-01-04,?
+`
+
+//Predicts the second class value
+type secondClass struct{}
+
+func (secondClass) BuildClassifier(instances data.Instances) error {
+	return nil
+}
+
+func (secondClass) DistributionForInstance(instance data.Instance) []float64 {
+	return []float64{0.25, 0.75}
+}
+
+//Evaluates the data twice with the predictions written in the format, the ID
+//is the date attribute
+func writePredictions(t *testing.T, format int) string {
+	t.Helper()
+	insts := readTestARFF(t, idsARFF)
+	e, err := NewEvaluation(insts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	output := NewPredictionOutput(&buf, format)
+	if err := output.SetIDAttribute(1); err != nil {
+		t.Fatal(err)
+	}
+	e.SetPredictionOutput(&output)
+	for run := 0; run < 2; run++ {
+		if _, err := e.EvaluateModel(secondClass{}, insts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.String()
+}
+
+func TestPredictionsCSV(t *testing.T) {
+	want := `inst,actual,predicted,error,prob_yes,prob_?,when
+1,yes,?,+,0.25,0.75,2024-01-02
+2,?,?,,0.25,0.75,?
+3,?,?,,0.25,0.75,2024-01-04
+1,yes,?,+,0.25,0.75,2024-01-02
+2,?,?,,0.25,0.75,?
+3,?,?,,0.25,0.75,2024-01-04
+`
+	if got := writePredictions(t, PREDICTIONS_CSV); got != want {
+		t.Errorf("CSV predictions:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestPredictionsARFF(t *testing.T) {
+	text := writePredictions(t, PREDICTIONS_ARFF)
+	insts, err := data.ReadARFF(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("predictions are not valid ARFF: %v\n%s", err, text)
+	}
+	if len(insts.Instances()) != 6 || insts.Instance(3).Value(0) != 1 {
+		t.Fatalf("predictions:\n%s", text)
+	}
+	//the class value "?" is quoted, a missing class is not
+	first, second := insts.Instance(0), insts.Instance(1)
+	if first.Value(2) != 1 || first.Value(3) != 1 {
+		t.Errorf("first prediction is not the error '?': %s", text)
+	}
+	if second.Value(1) != 1 || second.Value(2) != 1 || second.Value(3) != 0 {
+		t.Errorf("second prediction is not a correct '?': %s", text)
+	}
+	if !insts.Instance(2).IsMissingValue(1) {
+		t.Errorf("missing actual class is not missing: %s", text)
+	}
+	last := len(insts.Attributes()) - 1
+	id := insts.Attribute(last)
+	if id.Values()[int(first.Value(last))] != "2024-01-02" || !second.IsMissingValue(last) {
+		t.Errorf("bad ID values: %s", text)
+	}
+}
+
+func TestPredictionsJSONLines(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(writePredictions(t, PREDICTIONS_JSONL)), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("%d lines, want 6", len(lines))
+	}
+	var p jsonPrediction
+	if err := json.Unmarshal([]byte(lines[5]), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Inst != 3 || p.Actual != nil || p.Predicted == nil || *p.Predicted != "?" || p.Error {
+		t.Errorf("last prediction: %s", lines[5])
+	}
+	if p.ID == nil || *p.ID != "2024-01-04" || p.Distribution["?"] != 0.75 {
+		t.Errorf("last prediction: %s", lines[5])
+	}
+}
+
+func TestPredictionsBadID(t *testing.T) {
+	insts := readTestARFF(t, idsARFF)
+	var buf bytes.Buffer
+	output := NewPredictionOutput(&buf, PREDICTIONS_CSV)
+	if err := output.SetIDAttribute(-2); err == nil {
+		t.Errorf("SetIDAttribute(-2) did not fail")
+	}
+	if err := output.SetIDAttribute(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := output.PrintPrediction(insts, 0, []float64{1, 0}, 0, insts.Instance(0)); err == nil {
+		t.Errorf("ID attribute out of range did not fail")
+	}
+}
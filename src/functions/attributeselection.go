@@ -4,7 +4,6 @@ import (
 	"github.com/project-mac/src/data"
 	"math"
 	//	"utils"
-	"fmt"
 )
 
 type AttributeSelection struct {
@@ -40,22 +39,25 @@ func NewAttributeSelection() AttributeSelection {
 }
 
 //Start the selection attributes process
-func (as *AttributeSelection) StartSelection(instances data.Instances) {
+func (as *AttributeSelection) StartSelection(instances data.Instances) error {
 	as.input = data.NewInstancesWithInst(instances, len(instances.Attributes()))
 	as.input = instances
 	as.hasClass = as.input.ClassIndex() >= 0
-	as.selectedAttributes = as.SelectAttributes(as.input)
+	selected, err := as.SelectAttributes(as.input)
+	if err != nil {
+		return err
+	}
+	as.selectedAttributes = selected
 	if len(as.selectedAttributes) == 0 {
-		panic("No selected attributes")
+		return ErrNoAttributesSelected
 	}
 	//Set output
-	fmt.Println(as.selectedAttributes, "as.selectedAttributes")
 	as.output = data.NewInstances()
+	header := as.input.Header()
 	attributes := make([]data.Attribute, 0)
 	for i := range as.selectedAttributes {
-		attributes = append(attributes, *as.input.Attribute(as.selectedAttributes[i]))
+		attributes = append(attributes, header.Attribute(as.selectedAttributes[i]))
 	}
-	fmt.Println(attributes, "attributes")
 	as.output.SetDatasetName(as.input.DatasetName())
 	as.output.SetAttributes(attributes)
 	if as.hasClass {
@@ -67,50 +69,23 @@ func (as *AttributeSelection) StartSelection(instances data.Instances) {
 		tmpInst = append(tmpInst, as.convertInstance(in))
 	}
 	as.output.SetInstances(tmpInst)
+	return nil
 }
 
-//Convert a single instance over
+//Convert a single instance over, keeping it sparse if it was
 func (as *AttributeSelection) convertInstance(inst data.Instance) data.Instance {
-	newVasl := make([]float64, 0, len(as.output.Attributes()))
+	newVals := make([]float64, len(as.selectedAttributes))
 	for i, current := range as.selectedAttributes {
-		fmt.Println(current, i, inst.RealValues())
-		newVasl =  append(newVasl,inst.Value(current))
-		//newVasl[i] = inst.Value(current)
-		//fmt.Println(newVasl[i], "newVasl[i]")
-	}
-	fmt.Println("----------------------------------------------")
-	newInst := data.NewInstance()
-//	newInst.SetNumAttributes(len(newVasl))
-	values_ := make([]float64, len(newVasl))
-	indices_ := make([]int, len(newVasl))
-	vals := 0
-	for i := 0; i < len(newVasl); i++ {
-		if newVasl[i] != 0 {
-			values_[vals] = newVasl[i]
-			indices_[vals] = i
-			vals++
-		}
+		newVals[i] = inst.Value(current)
 	}
-	values := make([]float64, vals)
-	indices := make([]int, vals)
-	copy(values, values_)
-	copy(indices, indices_)
-//	fmt.Println(values, "values")
-//	fmt.Println(indices, "indices")
-	for k, i := range indices {
-		if as.output.Attribute(i).IsNominal() {
-			newInst.AddValues(as.output.Attribute(i).Values()[int(values[k])])
-		} else {
-			newInst.AddValues(as.output.Attribute(i).Name())
-		}
+	newInst := data.NewDenseInstance(inst.Weight(), newVals)
+	if inst.IsSparse() {
+		return data.NewSparseInstanceFrom(newInst)
 	}
-	newInst.SetIndices(indices)
-	newInst.SetRealValues(values)
-	newInst.SetWeight(inst.Weight())
 	return newInst
 }
 
-func (as *AttributeSelection) SelectAttributes(data_ data.Instances) []int {
+func (as *AttributeSelection) SelectAttributes(data_ data.Instances) ([]int, error) {
 	//***********attributeSet := make([]int, 0)
 	as.trainInstances = data_
 	as.doRank = as.search.GenerateRanking()
@@ -119,21 +94,28 @@ func (as *AttributeSelection) SelectAttributes(data_ data.Instances) []int {
 		as.trainInstances.SetClassIndex(len(as.trainInstances.Attributes()) - 1)
 	}
 	// Initialize the attribute evaluator
-	as.evaluator.BuildEvaluator(as.trainInstances)
+	if err := as.evaluator.BuildEvaluator(as.trainInstances); err != nil {
+		return nil, err
+	}
 	//fieldWith := int(math.Log(float64(len(as.trainInstances.Attributes()) + 1)))
 	// Do the search
 	//***********attributeSet =
-	as.search.Search(as.evaluator, as.trainInstances)
+	if _, err := as.search.Search(as.evaluator, as.trainInstances); err != nil {
+		return nil, err
+	}
 	// InfoGain do not implements postprocessing in weka
 
 	//I won't use this check because in this implementation it will always be true
 	//due that search method always is going to be Ranker
 	if as.doRank {
 	}
-	as.attributeRanking = as.search.rankedAttributes()
+	ranking, err := as.search.rankedAttributes()
+	if err != nil {
+		return nil, err
+	}
+	as.attributeRanking = ranking
 	// retrieve the number of attributes to retain
 	as.numToSelect = as.search.GetCalculatedNumToSelect()
-	fmt.Println(as.numToSelect, "as.numToSelect")
 	// determine fieldwidth for merit
 	f_p, w_p := 0, 0
 	for i := 0; i < as.numToSelect; i++ {
@@ -166,9 +148,10 @@ func (as *AttributeSelection) SelectAttributes(data_ data.Instances) []int {
 	for i := 0; i < as.numToSelect; i++ {
 		as.selectedAttributeSet[i] = int(as.attributeRanking[i][0])
 	}
-	fmt.Println(as.selectedAttributeSet, "as.selectedAttributeSet")
 	if as.doXval {
-		as.CrossValidateAttribute()
+		if err := as.CrossValidateAttribute(); err != nil {
+			return nil, err
+		}
 	}
 	if as.selectedAttributeSet != nil && !as.doXval {
 		as.attributeFilter = NewRemove()
@@ -177,22 +160,26 @@ func (as *AttributeSelection) SelectAttributes(data_ data.Instances) []int {
 		as.attributeFilter.SetInputFormat(as.trainInstances)
 	}
 	as.trainInstances = data.NewInstancesWithInst(as.trainInstances, 0)
-	return as.selectedAttributeSet
+	return as.selectedAttributeSet, nil
 }
 
-func (as *AttributeSelection) CrossValidateAttribute() {
+func (as *AttributeSelection) CrossValidateAttribute() error {
 	cvData := as.trainInstances
-	var train data.Instances
 	cvData.Randomize(as.seed)
 	for i := 0; i < as.numFolds; i++ {
-		train = cvData.TrainCV(as.numFolds, i, as.seed)
-		as.selectAttributesCVSplit(train)
+		train, err := cvData.TrainCV(as.numFolds, i, as.seed)
+		if err != nil {
+			return err
+		}
+		if err := as.selectAttributesCVSplit(train); err != nil {
+			return err
+		}
 	}
-
+	return nil
 }
 
 //Select attributes for a split of the data
-func (as *AttributeSelection) selectAttributesCVSplit(split data.Instances) {
+func (as *AttributeSelection) selectAttributesCVSplit(split data.Instances) error {
 	attributeRanking := make([][]float64, 0)
 	//this is only helpfull if this method is called from outside not from inner method of the object
 	//	if as.trainInstances.(nil)  {
@@ -208,11 +195,19 @@ func (as *AttributeSelection) selectAttributesCVSplit(split data.Instances) {
 		}
 
 	}
-	as.evaluator.BuildEvaluator(split)
+	if err := as.evaluator.BuildEvaluator(split); err != nil {
+		return err
+	}
 	// Do the search
-	attributeSet := as.search.Search(as.evaluator, split)
+	attributeSet, err := as.search.Search(as.evaluator, split)
+	if err != nil {
+		return err
+	}
 	if as.doRank {
-		attributeRanking = as.search.rankedAttributes()
+		attributeRanking, err = as.search.rankedAttributes()
+		if err != nil {
+			return err
+		}
 		for j := range attributeRanking {
 			// merit
 			as.rankResults[0][int(attributeRanking[j][0])] += attributeRanking[j][1]
@@ -229,6 +224,7 @@ func (as *AttributeSelection) selectAttributesCVSplit(split data.Instances) {
 		}
 	}
 	as.trials++
+	return nil
 }
 
 func (as *AttributeSelection) SetEvaluator(eval InfoGain) {
@@ -254,17 +250,16 @@ func NewRemove() Remove {
 
 func (r *Remove) SetInputFormat(instInfo data.Instances) {
 	r.getSelectedAttributes(len(instInfo.Attributes()))
+	header := instInfo.Header()
 	attributes := make([]data.Attribute, 0)
 	outputClass := -1
 	for _, current := range r.selectedAttributes {
 		if instInfo.ClassIndex() == current {
 			outputClass = len(attributes)
 		}
-		keep := *instInfo.Attribute(current)
-		fmt.Println(keep.Name())
+		keep := header.Attribute(current)
 		attributes = append(attributes, keep)
 	}
-	fmt.Println(len(attributes), "attributes", "\n", outputClass, "outputClass")
 	r.outputFormat = data.NewInstancesWithClassIndex(outputClass)
 	r.outputFormat.SetAttributes(attributes)
 }
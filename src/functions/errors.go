@@ -0,0 +1,22 @@
+package functions
+
+import (
+	"errors"
+	"fmt"
+)
+
+//Returned when the ranked attribute list is requested before a search
+var ErrNotSearched = errors.New("First execute the search to obtain the ranked attribute list")
+
+//Returned when the attribute selection does not keep any attribute
+var ErrNoAttributesSelected = errors.New("No selected attributes")
+
+//Error in a range of attributes such as "1,3-5"
+type RangeError struct {
+	Range string
+	Msg   string
+}
+
+func (e *RangeError) Error() string {
+	return fmt.Sprintf("Bad range '%s': %s", e.Range, e.Msg)
+}
@@ -24,16 +24,21 @@ func NewInfoGain() InfoGain {
 	return ig
 }
 
-func (ig *InfoGain) BuildEvaluator(instances data.Instances) {
+func (ig *InfoGain) BuildEvaluator(instances data.Instances) error {
 	classIndex := instances.ClassIndex()
 	numInstances := len(instances.Instances())
+	if classIndex < 0 || classIndex >= len(instances.Attributes()) {
+		return data.ErrClassNotSet
+	}
+	if !instances.Attribute(classIndex).IsNominal() {
+		return fmt.Errorf("InfoGain: class attribute '%s' must be nominal", instances.Attribute(classIndex).Name())
+	}
 
 	if ig.binarize { //binarize instances
 		//implement NumericToBinary function
 		ntb := NewNumericToBinary()
 		ntb.Exec(instances)
 		instances =  ntb.Output()
-		fmt.Println(instances.Instances())
 	} else { //discretize instances
 		//implement Discretize function
 	}
@@ -51,18 +56,15 @@ func (ig *InfoGain) BuildEvaluator(instances data.Instances) {
 		}
 	}
 	// Initialize counters
-	fmt.Println(numClasses, "numclasses")
 	temp := make([]float64, numClasses+1)
 	for k := 0; k < numInstances; k++ {
 		inst := instances.Instance(k)
 		if inst.ClassMissing(classIndex) { //check that class if the class is missing /*implement method to do that*/
 			temp[numClasses] += inst.Weight()
 		} else {
-			fmt.Println(int(inst.ClassValue(classIndex)), "classIndexes", inst.Weight(), "weights")
 			temp[int(inst.ClassValue(classIndex))] += inst.Weight() //get the index of the value of the class
 		}
 	}
-	fmt.Println(temp)
 	for k := range counts {
 		if k != classIndex {
 			for i := range temp {
@@ -70,28 +72,41 @@ func (ig *InfoGain) BuildEvaluator(instances data.Instances) {
 			}
 		}
 	}
-	// Get counts
-	//inst.RealValues()[classIndex]) check this after finish, may contains errors, its have to be check if the classIndex exists if not return 0 /*see weka*/
-	//implement the necessary methods to make easier this implementation and not bugs friendly
-	//New methods already implemented!!!!!!!! Later check it's functioning
-	for k := 0; k < numInstances; k++ {
-		inst := instances.Instance(k)
-		for i := range inst.RealValues() {
-			if inst.Index(i) != classIndex {
-				if inst.IsMissingValue(i) || inst.ClassMissing(classIndex) { //if is missing the real value and the class
-					if !inst.IsMissingValue(i) {
-						counts[inst.Index(i)][int(inst.ValueSparse(i))][numClasses] += inst.Weight()
-						counts[inst.Index(i)][0][numClasses] -= inst.Weight()
-					} else if !inst.IsMissingValue(classIndex) {
-						counts[inst.Index(i)][instances.Attribute(inst.Index(i)).NumValues()][int(inst.ClassValue(classIndex))] += inst.Weight() //tongue twister, now its not
-						counts[inst.Index(i)][0][int(inst.ClassValue(classIndex))] -= inst.Weight()
-					} else {
-						counts[inst.Index(i)][instances.Attribute(inst.Index(i)).NumValues()][numClasses] += inst.Weight()
-						counts[inst.Index(i)][0][numClasses] -= inst.Weight()
-					}
-				} else {
-					counts[inst.Index(i)][int(inst.ValueSparse(i))][int(inst.ClassValue(classIndex))] += inst.Weight()
-					counts[inst.Index(i)][0][int(inst.ClassValue(classIndex))] -= inst.Weight()
+	// Get counts, the weight of each instance moves from the first value of
+	// the attribute, where the class counts start, to the instance's value,
+	// or to the last row/column if the value/class is missing
+	count := func(k int, value, classValue, weight float64) {
+		row, column := len(counts[k])-1, numClasses
+		if !math.IsNaN(value) {
+			row = int(value)
+		}
+		if !math.IsNaN(classValue) {
+			column = int(classValue)
+		}
+		counts[k][row][column] += weight
+		counts[k][0][column] -= weight
+	}
+	if m := instances.CSR(); m != nil && !m.Detached() {
+		// Go through the columns of the matrix, zeros are not stored
+		classValues := make([]float64, m.NumRows())
+		rows, values := m.Column(classIndex)
+		for j, r := range rows {
+			classValues[r] = values[j]
+		}
+		for k := range counts {
+			if k != classIndex {
+				rows, values := m.Column(k)
+				for j, r := range rows {
+					count(k, values[j], classValues[r], m.Weight(int(r)))
+				}
+			}
+		}
+	} else {
+		for k := 0; k < numInstances; k++ {
+			inst := instances.Instance(k)
+			for i := 0; i < inst.NumValues(); i++ {
+				if inst.Index(i) != classIndex {
+					count(inst.Index(i), inst.ValueSparse(i), inst.ClassValue(classIndex), inst.Weight())
 				}
 			}
 		}
@@ -158,6 +173,7 @@ func (ig *InfoGain) BuildEvaluator(instances data.Instances) {
 		}
 	}
 	//fmt.Println(ig.infoGains, "infogain")
+	return nil
 }
 
 func entropyOverColumns(matrix [][]float64) float64 {
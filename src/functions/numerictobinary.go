@@ -25,28 +25,16 @@ func (ntb *NumericToBinary) Exec(instances data.Instances) {
 }
 
 func (ntb *NumericToBinary) convertInstance(instance data.Instance) data.Instance {
-	inst := data.NewInstance()
-	vals := make([]float64, len(instance.RealValues()))
-	newIndexes := make([]int, len(instance.RealValues()))
-	for j := range instance.RealValues() {
-		att := ntb.input.Attribute(instance.Index(j))
-		if att.Type() != data.NUMERIC || instance.Index(j) == ntb.input.ClassIndex() {
-			//fmt.Println(ntb.input.ClassIndex())
-			vals[j] = instance.ValueSparse(j)
-		} else {
-			if instance.IsMissingValue(j) {
-				//fmt.Println("DSAD")
-				vals[j] = instance.ValueSparse(j)
-			} else {
-				//fmt.Println("DSAD---")
-				vals[j] = 1
+	inst := instance.Copy()
+	for j := 0; j < inst.NumValues(); j++ {
+		att := ntb.input.Attribute(inst.Index(j))
+		if att.Type() == data.NUMERIC && inst.Index(j) != ntb.input.ClassIndex() && !inst.IsMissingSparse(j) {
+			//zero values stay zero, so sparse instances keep their indices
+			if inst.ValueSparse(j) != 0 {
+				inst.SetValueSparse(j, 1)
 			}
 		}
-		newIndexes[j] = instance.Index(j)
 	}
-	inst.SetWeight(instance.Weight())
-	inst.SetRealValues(vals)
-	inst.SetIndices(newIndexes)
 	return inst
 }
 
@@ -62,7 +50,7 @@ func (ntb *NumericToBinary) SetOutput() {
 	vals := make([]string, 2)
 
 	// Compute new attributes
-	for j, att := range ntb.input.Attributes() {
+	for j, att := range ntb.input.Header().Attributes() {
 		if j == newClassIndex || att.Type() != data.NUMERIC {
 			newAtts = append(newAtts,att)
 		} else {
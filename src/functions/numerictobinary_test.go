@@ -0,0 +1,70 @@
+package functions
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/project-mac/src/data"
+)
+
+//Dataset with missing numeric, string and class values
+const missingARFF = `@relation missing
+@attribute size numeric
+@attribute text string
+@attribute weight numeric
+@attribute class {yes,no}
+@data
+3,'good day',0,yes
+?,'bad day',2.5,no
+0,?,?,yes
+5,'good night',1,?
+`
+
+func readMissingARFF(t *testing.T) data.Instances {
+	t.Helper()
+	insts, err := data.ReadARFF(strings.NewReader(missingARFF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	insts.SetClassIndex(3)
+	return insts
+}
+
+func TestNumericToBinaryKeepsMissing(t *testing.T) {
+	insts := readMissingARFF(t)
+	for _, sparse := range []bool{false, true} {
+		input := insts
+		if sparse {
+			input = data.NewInstancesWithInst(insts, len(insts.Instances()))
+			rows := make([]data.Instance, len(insts.Instances()))
+			for i, inst := range insts.Instances() {
+				rows[i] = data.NewSparseInstanceFrom(inst)
+			}
+			input.SetInstances(rows)
+		}
+		ntb := NewNumericToBinary()
+		ntb.Exec(input)
+		output := ntb.Output()
+		missing := math.NaN()
+		//the string attribute keeps the indexes of its values
+		want := [][]float64{
+			{1, 0, 0, 0},
+			{missing, 1, 1, 1},
+			{0, missing, missing, 0},
+			{1, 2, 1, missing},
+		}
+		for i, values := range want {
+			inst := output.Instance(i)
+			for j, value := range values {
+				if got := inst.Value(j); got != value && !(math.IsNaN(got) && math.IsNaN(value)) {
+					t.Errorf("sparse %v: instance %d attribute %d = %v, want %v", sparse, i, j, got, value)
+				}
+			}
+		}
+		size := output.Attribute(0)
+		if size.Name() != "size_binarize" || !size.IsNominal() {
+			t.Errorf("binarized attribute %q is not nominal", size.Name())
+		}
+	}
+}
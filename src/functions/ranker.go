@@ -36,7 +36,7 @@ func NewRanker() Ranker {
 //Kind of a dummy search algorithm. Calls a Attribute evaluator to
 //evaluate each attribute not included in the notInclude array and then sorts
 //them to produce a ranked list of attributes.
-func (r *Ranker) Search(evaluator InfoGain, instances data.Instances) []int {
+func (r *Ranker) Search(evaluator InfoGain, instances data.Instances) ([]int, error) {
 	var i, j int
 	r.numAttributes = len(instances.Attributes())
 	r.classIndex = instances.ClassIndex()
@@ -82,20 +82,23 @@ func (r *Ranker) Search(evaluator InfoGain, instances data.Instances) []int {
 		r.attrMerit[i] = evaluator.evaluateAttribute(r.attrList[i])
 	}
 	fmt.Println(r.attrMerit, "merrit")
-	tempRanked := r.rankedAttributes()
+	tempRanked, err := r.rankedAttributes()
+	if err != nil {
+		return nil, err
+	}
 	rankedAttributes := make([]int, len(r.attrList))
 	for i := range rankedAttributes {
 		rankedAttributes[i] = int(tempRanked[i][0])
 	}
 	fmt.Println(rankedAttributes, "rankedAttributes")
-	return rankedAttributes
+	return rankedAttributes, nil
 }
 
 //Sorts the evaluated attribute list
-func (r *Ranker) rankedAttributes() [][]float64 {
+func (r *Ranker) rankedAttributes() ([][]float64, error) {
 	var i, j int
 	if len(r.attrList) == 0 || len(r.attrMerit) == 0 {
-		panic("Fisrt execute the search to obtain the ranked attribute list")
+		return nil, ErrNotSearched
 	}
 	rank := make([]int, len(r.attrMerit))
 	h := 0
@@ -121,7 +124,7 @@ func (r *Ranker) rankedAttributes() [][]float64 {
 		bestToWorst[i][1] = r.attrMerit[temp]
 	}
 	if r.numToSelect > len(bestToWorst) {
-		panic("More attributes requested than exist in the data")
+		return nil, fmt.Errorf("More attributes requested than exist in the data")
 	}
 	if r.numToSelect <= 0 {
 		if r.threshold == -math.MaxFloat64 {
@@ -131,7 +134,7 @@ func (r *Ranker) rankedAttributes() [][]float64 {
 		}
 	}
 	fmt.Println(bestToWorst, "bestToWorst")
-	return bestToWorst
+	return bestToWorst, nil
 }
 
 func (r *Ranker) determineNumToSelectFromThreshold(ranking [][]float64) {
@@ -163,9 +166,10 @@ func (r *Ranker) inNotInclude(f int) bool {
 	return false
 }
 
-func (r *Ranker) SetRange(rang string) {
+//Sets the 1-based attributes not to rank, e.g. "1,3-5"
+func (r *Ranker) SetRange(rang string) error {
 	if strings.EqualFold(rang, "") {
-		panic("The range cannot be empty")
+		return &RangeError{rang, "The range cannot be empty"}
 	}
 	selected := make([]int, 0)
 	attrs := strings.Split(rang, ",")
@@ -173,12 +177,12 @@ func (r *Ranker) SetRange(rang string) {
 		if strings.Contains(attr, "-") {
 			bounds := strings.Split(attr, "-")
 			if len(bounds) > 2 {
-				panic("It is only permitted to establish a lower bound and an upper bound")
+				return &RangeError{rang, "It is only permitted to establish a lower bound and an upper bound"}
 			}
 			lowBound, err1 := strconv.ParseInt(bounds[0], 10, 0)
 			upBound, err2 := strconv.ParseInt(bounds[1], 10, 0)
-			if err1 != nil || err2 != nil {
-				panic(fmt.Errorf("Make sure the bound %s is correctly defined, allow nummber-number only", attr))
+			if err1 != nil || err2 != nil || lowBound < 1 || upBound < lowBound {
+				return &RangeError{rang, fmt.Sprintf("Make sure the bound %s is correctly defined, allow nummber-number only", attr)}
 			}
 			lowBound = lowBound - 1
 			upBound = upBound - 1
@@ -189,8 +193,8 @@ func (r *Ranker) SetRange(rang string) {
 
 		} else {
 			index, err := strconv.ParseInt(attr, 10, 0)
-			if err != nil {
-				panic(fmt.Errorf("Only numbers allow in %s ", attr))
+			if err != nil || index < 1 {
+				return &RangeError{rang, fmt.Sprintf("Only numbers allow in %s ", attr)}
 			}
 			index = index - 1
 			selected = append(selected, int(index))
@@ -199,6 +203,7 @@ func (r *Ranker) SetRange(rang string) {
 		r.notInclude = selected
 		r.isRangeInUse = true
 	}
+	return nil
 }
 
 func (r *Ranker) GenerateRanking() bool {
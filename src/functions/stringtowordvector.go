@@ -75,7 +75,7 @@ func NewStringToWordVectorInst(inputData data.Instances) StringToWordVector {
 }
 
 //Start the execution of the function
-func (stwv *StringToWordVector) Exec() data.Instances {
+func (stwv *StringToWordVector) Exec() (data.Instances, error) {
 	/* TODO: first check that the input format is initialized*/
 	//	if stwv.inputFormat != nil {
 	//		panic("No input instace defined")
@@ -84,12 +84,13 @@ func (stwv *StringToWordVector) Exec() data.Instances {
 	if stwv.firstTime {
 		//check if the class attribute is not nominal to turn off the per-class basis
 		//fmt.Println(inst.ClassIndex(), "classIndex")
-		classAttr := inst.Attributes()[inst.ClassIndex()]
-		if classAttr.Type() != data.NOMINAL {
+		if inst.ClassIndex() < 0 || inst.Attributes()[inst.ClassIndex()].Type() != data.NOMINAL {
 			stwv.perClass = false
 		}
 		//Determine the dictionary for the input format
-		stwv.determineDictionary(inst)
+		if err := stwv.determineDictionary(inst); err != nil {
+			return stwv.outputFormat, err
+		}
 		//Convert all instances without normalize
 		fv := make([]data.Instance, 0)
 		firstCopy := 0
@@ -103,10 +104,9 @@ func (stwv *StringToWordVector) Exec() data.Instances {
 		stwv.avgDocLength = 0
 		for _, inst := range fv {
 			docLength := float64(0)
-			for j := 0; j < len(inst.RealValues()); j++ {
-				if inst.Indices()[j] >= firstCopy {
-					//fmt.Println("inst.RealValues()[j] ", inst.RealValues()[j])
-					docLength += inst.RealValues()[j] * inst.RealValues()[j]
+			for j := 0; j < inst.NumValues(); j++ {
+				if inst.Index(j) >= firstCopy {
+					docLength += inst.ValueSparse(j) * inst.ValueSparse(j)
 				}
 			}
 			fmt.Println("docLength ", docLength)
@@ -116,7 +116,9 @@ func (stwv *StringToWordVector) Exec() data.Instances {
 		// Perform normalization if necessary.
 		if stwv.normalize {
 			for _, inst := range fv {
-				stwv.normalizeInstance(&inst, firstCopy)
+				if err := stwv.normalizeInstance(inst, firstCopy); err != nil {
+					return stwv.outputFormat, err
+				}
 			}
 		}
 		stwv.outputFormat.SetInstances(fv)
@@ -130,16 +132,18 @@ func (stwv *StringToWordVector) Exec() data.Instances {
 		}
 		if stwv.normalize {
 			for _, inst := range fv {
-				stwv.normalizeInstance(&inst, firstCopy)
+				if err := stwv.normalizeInstance(inst, firstCopy); err != nil {
+					return stwv.outputFormat, err
+				}
 			}
 		}
 	}
 	fmt.Println("Done!")
 	stwv.firstTime = false
-	return stwv.outputFormat
+	return stwv.outputFormat, nil
 }
 
-func (stwv *StringToWordVector) determineDictionary(inst *data.Instances) {
+func (stwv *StringToWordVector) determineDictionary(inst *data.Instances) error {
 	/* TODO: see if use a stopwords list*/
 	fmt.Println("Determing dictionary!")
 	classInd := inst.ClassIndex()
@@ -156,7 +160,10 @@ func (stwv *StringToWordVector) determineDictionary(inst *data.Instances) {
 	for i, instance := range inst.Instances() {
 		vInd := int(0)
 		if stwv.perClass && (classInd != -1) {
-			vInd = int(instance.RealValues()[classInd])
+			//instances with a missing class count in the first class, as weka
+			if !instance.ClassMissing(classInd) {
+				vInd = int(instance.ClassValue(classInd))
+			}
 		}
 		//Iterate through all relevant string attributes of the current instance
 		hashtable := make(map[string]int, 0)
@@ -164,8 +171,7 @@ func (stwv *StringToWordVector) determineDictionary(inst *data.Instances) {
 			if !instance.IsMissingValue(j) && inst.Attributes()[j].IsString() {
 				// Iterate through tokens, perform stemming, and remove stopwords
 				// (if required)
-				//fmt.Println(instance.Values())
-				words := strings.Fields(instance.Values()[j])
+				words := strings.Fields(inst.Attributes()[j].Values()[int(instance.Value(j))])
 				for _, word := range words {
 					_, present := hashtable[word]
 					if !present {
@@ -197,7 +203,7 @@ func (stwv *StringToWordVector) determineDictionary(inst *data.Instances) {
 				dicA[vInd].Insert(word, count)
 				//fmt.Println(word, " ",dicA[vInd][word])
 			} else {
-				panic("Check the code, there must be a word in the dictionary")
+				return fmt.Errorf("StringToWordVector: word '%s' is missing from the dictionary", word)
 			}
 			fmt.Println(dicA[vInd].Find(word))
 		}
@@ -266,7 +272,7 @@ func (stwv *StringToWordVector) determineDictionary(inst *data.Instances) {
 	fmt.Println(totalSize+len(inst.Attributes()), "len(attributes)")
 	// Add the non-converted attributes
 	classIndex := int(-1)
-	for i, attr := range stwv.inputFormat.Attributes() {
+	for i, attr := range stwv.inputFormat.Header().Attributes() {
 		if !attr.IsString() {
 			if inst.ClassIndex() == i {
 				classIndex = len(attributes)
@@ -346,6 +352,7 @@ func (stwv *StringToWordVector) determineDictionary(inst *data.Instances) {
 	stwv.outputFormat = data.NewInstances()
 	stwv.outputFormat.SetAttributes(attributes)
 	stwv.outputFormat.SetClassIndex(classIndex)
+	return nil
 }
 
 func (stwv *StringToWordVector) convertInstancewoDocNorm(inst data.Instance) (int, data.Instance) {
@@ -360,19 +367,16 @@ func (stwv *StringToWordVector) convertInstancewoDocNorm(inst data.Instance) (in
 		//fmt.Println("input attrs: ", i)
 		if !stwv.inputFormat.Attributes()[i].IsString() {
 			// Add simple nominal and numeric attributes directly
-			if inst.RealValues()[i] != 0 {
-				contained.Insert(firstCopy, inst.RealValues()[i])
+			if inst.Value(i) != 0 {
+				contained.Insert(firstCopy, inst.Value(i))
 				mapKeys = append(mapKeys, float64(firstCopy))
 				firstCopy++
 			} else {
 				firstCopy++
 			}
-		} else if inst.IsMissingValue(i) {
-			//fmt.Println("print 1.2")
-			contained.Insert(firstCopy, inst.MissingValue)
-			mapKeys = append(mapKeys, float64(firstCopy))
-			firstCopy++
 		} else if stwv.inputFormat.Attributes()[i].IsString() {
+			//string attributes are not copied to the output, a missing text
+			//just adds no words
 			//if i have to implement the range selector then code this part
 		}
 	}
@@ -382,7 +386,7 @@ func (stwv *StringToWordVector) convertInstancewoDocNorm(inst data.Instance) (in
 		//fmt.Println("print 2.0.1" , stwv.inputFormat.Attributes()[1].IsString())
 		if stwv.inputFormat.Attributes()[j].IsString() && inst.IsMissingValue(j) == false {
 			//fmt.Println("print 2")
-			words := strings.Fields(inst.Values()[j])
+			words := strings.Fields(stwv.inputFormat.Attributes()[j].Values()[int(inst.Value(j))])
 			//fmt.Println(stwv.dictionary)
 			//fmt.Println("------------------------------------------------")
 			for _, word := range words {
@@ -496,51 +500,36 @@ func (stwv *StringToWordVector) convertInstancewoDocNorm(inst data.Instance) (in
 		indices[i] = index
 		i++
 	})
-	instSparse := data.NewInstance()
-	for k, i := range indices {
-		if stwv.outputFormat.Attributes()[i].IsNominal() {
-			instSparse.AddValues(stwv.outputFormat.Attributes()[i].Values()[int(values[k])])
-		} else if stwv.outputFormat.Attributes()[i].IsNominal() && !stwv.outputFormat.Attributes()[i].IsString() {
-			instSparse.AddValues(stwv.outputFormat.Attributes()[i].Values()[i])
-		} else {
-			instSparse.AddValues(stwv.outputFormat.Attributes()[i].Name())
-		}
-
-	}
-	instSparse.SetIndices(indices)
-	instSparse.SetRealValues(values)
-	instSparse.SetWeight(inst.Weight())
-	instSparse.SetNumAttributes(len(values))
-	return firstCopy, instSparse
+	return firstCopy, data.NewSparseInstance(inst.Weight(), values, indices, len(stwv.outputFormat.Attributes()))
 }
 
-func (stwv *StringToWordVector) normalizeInstance(inst *data.Instance, firstCopy int) {
+func (stwv *StringToWordVector) normalizeInstance(inst data.Instance, firstCopy int) error {
 	//fmt.Println("firstcopy ", firstCopy)
 	//fmt.Println("avgdoclength ", stwv.avgDocLength)
 	docLength := float64(0)
 	if stwv.avgDocLength < 0 {
-		panic("Average document length not set.")
+		return fmt.Errorf("StringToWordVector: average document length not set")
 	}
-	//	fmt.Println("valores: ", inst.RealValues())
 	// Compute length of document vector
-	for j := 0; j < len(inst.RealValues()); j++ {
-		if inst.Indices()[j] >= firstCopy {
-			docLength += inst.RealValues()[j] * inst.RealValues()[j]
+	for j := 0; j < inst.NumValues(); j++ {
+		if inst.Index(j) >= firstCopy {
+			docLength += inst.ValueSparse(j) * inst.ValueSparse(j)
 		}
 	}
 	docLength = math.Sqrt(docLength)
 	// Normalize document vector
-	for j := 0; j < len(inst.RealValues()); j++ {
-		if inst.Indices()[j] >= firstCopy {
-			val := inst.RealValues()[j] * stwv.avgDocLength / docLength
-			inst.AddRealValuesIndex(j, val)
-			if val == 0 {
-				fmt.Println("Setting value %d to zero", inst.Indices()[j])
+	for j := 0; j < inst.NumValues(); j++ {
+		if idx := inst.Index(j); idx >= firstCopy {
+			val := inst.ValueSparse(j) * stwv.avgDocLength / docLength
+			inst.SetValue(idx, val)
+			//a sparse instance drops the zero value, so the next one is at j
+			if val == 0 && inst.IsSparse() {
+				fmt.Printf("Setting value %d to zero\n", idx)
 				j--
 			}
 		}
 	}
-
+	return nil
 }
 
 func (stwv *StringToWordVector) ConvertedInstances() data.Instances {
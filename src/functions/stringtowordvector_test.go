@@ -0,0 +1,41 @@
+package functions
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStringToWordVectorKeepsMissing(t *testing.T) {
+	insts := readMissingARFF(t)
+	stwv := NewStringToWordVectorInst(insts)
+	output, err := stwv.Exec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	//size, weight and class are copied first, then come the words
+	classIndex := output.ClassIndex()
+	if classIndex != 2 || len(output.Attributes()) != 7 || len(output.Instances()) != 4 {
+		t.Fatalf("output has class %d, %d attributes and %d instances, want 2, 7 and 4", classIndex, len(output.Attributes()), len(output.Instances()))
+	}
+	if !math.IsNaN(output.Instance(1).Value(0)) || !math.IsNaN(output.Instance(2).Value(1)) {
+		t.Errorf("missing numeric values became %v and %v", output.Instance(1).Value(0), output.Instance(2).Value(1))
+	}
+	if output.Instance(0).ClassMissing(classIndex) || !output.Instance(3).ClassMissing(classIndex) {
+		t.Errorf("class missing %v and %v, want false and true", output.Instance(0).ClassMissing(classIndex), output.Instance(3).ClassMissing(classIndex))
+	}
+	for i, inst := range output.Instances() {
+		words := 0
+		for j := 0; j < inst.NumValues(); j++ {
+			if inst.Index(j) > classIndex {
+				words++
+				if inst.IsMissingSparse(j) {
+					t.Errorf("instance %d has a missing word count at %d", i, inst.Index(j))
+				}
+			}
+		}
+		//the missing text adds no words
+		if want := map[bool]int{true: 0, false: 2}[i == 2]; words != want {
+			t.Errorf("instance %d has %d words, want %d", i, words, want)
+		}
+	}
+}
@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"github.com/project-mac/src/functions"
 	"github.com/project-mac/src/utils"
+	"log"
 )
 
 func main() {
 	instances := data.NewInstancesWithClassIndex(0)
-	instances.ParseFile("C:\\Users\\Yuri\\workspace\\SMO\\src\\main\\_AppsLemmas.arff")
+	if err := instances.ParseFile("C:\\Users\\Yuri\\workspace\\SMO\\src\\main\\_AppsLemmas.arff"); err != nil {
+		log.Fatal(err)
+	}
 	//	for _, attr := range instances.Attributes() {
 	//		fmt.Println(attr.Name(), attr.Type())
 	//		for idx, val := range attr.Values() {
@@ -28,7 +31,10 @@ func main() {
 	stwv.SetWordsToKeep(15)
 	stwv.SetPerClass(true)
 	stwv.SetNormalize(false)
-	processed := stwv.Exec()
+	processed, err := stwv.Exec()
+	if err != nil {
+		log.Fatal(err)
+	}
 	processed.ClassIndex()
 	//	for _, attr := range processed.Attributes() {
 	//		fmt.Println(attr.Name(), attr.Type())
@@ -39,9 +45,7 @@ func main() {
 	//	}
 	//fmt.Println(processed.Attributes()[0].Values())
 	for _, inst := range processed.Instances() {
-		fmt.Println(inst.Values())
-		fmt.Println(inst.RealValues())
-		fmt.Println(inst.Indices())
+		fmt.Println(inst)
 	}
 	ig := functions.NewInfoGain()
 	ranker := functions.NewRanker()
@@ -52,15 +56,17 @@ func main() {
 	as := functions.NewAttributeSelection()
 	as.SetEvaluator(ig)
 	as.SetSearchMethod(ranker)
-	as.StartSelection(processed)
+	if err := as.StartSelection(processed); err != nil {
+		log.Fatal(err)
+	}
 	processed = as.Output()
 	for _, inst := range processed.Instances() {
-		fmt.Println(inst.Values())
-		fmt.Println(inst.RealValues())
-		fmt.Println(inst.Indices())
+		fmt.Println(inst)
 	}
 	//ig.BuildEvaluator(processed)
 	i := utils.SortFloat([]float64{0, 0, 0, 0, 0, 0, 0.8904916402194916, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0.7793498372920848, 0, 0, 0, 0, 0.7793498372920848})
 	fmt.Println(i)
-	data.ExportToArffFileSparse(processed, "", "")
+	if err := data.ExportToArffFileSparse(processed, "", ""); err != nil {
+		log.Fatal(err)
+	}
 }